@@ -20,6 +20,22 @@ func Avg(name, field string) *Aggregation {
 	return New(name, field, "avg", nil)
 }
 
+// Clone creates a deep copy of the aggregation
+// Maps and slices are copied, other values are shared
+func (a *Aggregation) Clone() *Aggregation {
+	var m = make(Aggregation)
+
+	for k, v := range *a {
+		m[k] = &data{
+			Name:     v.Name,
+			Operator: clone(v.Operator),
+			Value:    clone(v.Value),
+		}
+	}
+
+	return &m
+}
+
 // Count creates and return a new count aggregation
 func Count(name, field string) *Aggregation {
 	return New(name, field, "count", nil)
@@ -120,6 +136,31 @@ func (a *Aggregation) Unit(unit string) *Aggregation {
 	return a
 }
 
+func clone(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		var m = make(map[string]interface{}, len(t))
+
+		for k, mv := range t {
+			m[k] = clone(mv)
+		}
+
+		return m
+	case []interface{}:
+		var list = make([]interface{}, len(t))
+
+		for i, lv := range t {
+			list[i] = clone(lv)
+		}
+
+		return list
+	case []qrange.Range:
+		return append([]qrange.Range{}, t...)
+	}
+
+	return v
+}
+
 func (a *Aggregation) getFieldName() string {
 	var field string
 	for k := range *a {
@@ -263,3 +263,25 @@ func TestTerms(t *testing.T) {
 }`
 	jsonlib.AssertJSONMarshal(t, want, aggregation)
 }
+
+func TestClone(t *testing.T) {
+	var want = `{
+    "f": {
+        "name": "a",
+        "operator": "geoDistance",
+        "value": {
+            "location": [0, 0],
+            "ranges": [{"to": 1}]
+        }
+    }
+}`
+
+	var original = Distance("a", "f", []int{0, 0}, qrange.To(1))
+	var got = original.Clone()
+
+	jsonlib.AssertJSONMarshal(t, want, got)
+
+	got.Range(qrange.From(2)).Unit("km")
+
+	jsonlib.AssertJSONMarshal(t, want, original)
+}
@@ -0,0 +1,204 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wedeploy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+
+	"github.com/henvic/wedeploy-sdk-go/query"
+)
+
+// DefaultBatchConcurrency is the number of operations of a batch sent
+// in parallel by default
+var DefaultBatchConcurrency = 4
+
+// Batch of Data operations sent together.
+// WeDeploy Data has no batch endpoint, so the operations are sent
+// as requests in bounded parallel.
+type Batch struct {
+	client      *HTTPClient
+	ops         []batchOp
+	concurrency int
+}
+
+type batchOp struct {
+	method  string
+	request *WeDeploy
+	err     error
+}
+
+// BatchResult of an operation of a batch.
+// The response is available on Request.Response, with its body read into
+// memory and its connection closed, so it doesn't need to be closed.
+type BatchResult struct {
+	Method  string
+	Request *WeDeploy
+	Err     error
+}
+
+// BatchError is returned when operations of a batch fail
+type BatchError struct {
+	// Failed is the position of the failed operations on the batch
+	Failed []int
+	Total  int
+}
+
+func (b BatchError) Error() string {
+	return fmt.Sprintf("%d of %d batch operations failed", len(b.Failed), b.Total)
+}
+
+// NewBatch creates a batch using the default client
+func NewBatch() *Batch {
+	return client.Batch()
+}
+
+// Batch creates a batch for the client
+func (h *HTTPClient) Batch() *Batch {
+	return &Batch{
+		client:      h,
+		concurrency: DefaultBatchConcurrency,
+	}
+}
+
+// Concurrency sets the number of operations sent in parallel
+func (b *Batch) Concurrency(n int) *Batch {
+	if n < 1 {
+		n = 1
+	}
+
+	b.concurrency = n
+	return b
+}
+
+// Len is the number of operations of the batch
+func (b *Batch) Len() int {
+	return len(b.ops)
+}
+
+// Add an operation sending the request with the method
+func (b *Batch) Add(method string, w *WeDeploy) *Batch {
+	b.ops = append(b.ops, batchOp{method: method, request: w})
+	return b
+}
+
+// Create a document on the collection
+func (b *Batch) Create(collection string, document interface{}) *Batch {
+	return b.addJSON("POST", b.client.URL(collection), document)
+}
+
+// Update fields of the document with the ID on the collection
+func (b *Batch) Update(collection, id string, document interface{}) *Batch {
+	return b.addJSON("PATCH", b.client.URL(collection, id), document)
+}
+
+// Replace the document with the ID on the collection
+func (b *Batch) Replace(collection, id string, document interface{}) *Batch {
+	return b.addJSON("PUT", b.client.URL(collection, id), document)
+}
+
+// Delete the document with the ID on the collection
+func (b *Batch) Delete(collection, id string) *Batch {
+	return b.Add("DELETE", b.client.URL(collection, id))
+}
+
+// Query the collection
+func (b *Batch) Query(collection string, q *query.Builder) *Batch {
+	var w = b.client.URL(collection)
+	w.Query = q
+	return b.Add("GET", w)
+}
+
+func (b *Batch) addJSON(method string, w *WeDeploy, document interface{}) *Batch {
+	var bin, err = json.Marshal(document)
+
+	w.Body(bytes.NewBuffer(bin))
+	b.ops = append(b.ops, batchOp{method: method, request: w, err: err})
+	return b
+}
+
+// Send the operations of the batch, returning their results in the same
+// order, and a BatchError if any of them failed.
+// The response bodies are read and closed, up to the maximum response size.
+// Operations not sent when the context is done fail with its error.
+func (b *Batch) Send(ctx context.Context) ([]BatchResult, error) {
+	var results = make([]BatchResult, len(b.ops))
+	var queue = make(chan int)
+	var wg sync.WaitGroup
+
+	for i := 0; i < b.concurrency && i < len(b.ops); i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for n := range queue {
+				results[n] = b.ops[n].send(ctx)
+			}
+		}()
+	}
+
+	for n := range b.ops {
+		select {
+		case queue <- n:
+		case <-ctx.Done():
+			results[n] = BatchResult{b.ops[n].method, b.ops[n].request, ctx.Err()}
+		}
+	}
+
+	close(queue)
+	wg.Wait()
+
+	var failed []int
+
+	for n, r := range results {
+		if r.Err != nil {
+			failed = append(failed, n)
+		}
+	}
+
+	if len(failed) != 0 {
+		return results, BatchError{Failed: failed, Total: len(results)}
+	}
+
+	return results, nil
+}
+
+func (o batchOp) send(ctx context.Context) BatchResult {
+	var r = BatchResult{Method: o.method, Request: o.request, Err: o.err}
+
+	if r.Err == nil {
+		r.Err = ctx.Err()
+	}
+
+	if r.Err != nil {
+		return r
+	}
+
+	if o.request.Context() == nil {
+		o.request.SetContext(ctx)
+	}
+
+	r.Err = o.request.action(o.method)
+
+	if r.Err == nil {
+		r.Err = bufferResponseBody(o.request.Response)
+	}
+
+	return r
+}
+
+// bufferResponseBody reads the response body into memory and closes it
+func bufferResponseBody(resp *http.Response) error {
+	var body, err = ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return err
+}
@@ -0,0 +1,219 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wedeploy
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/henvic/wedeploy-sdk-go/query"
+)
+
+func TestBatch(t *testing.T) {
+	var active, peak int32
+
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var n = atomic.AddInt32(&active, 1)
+
+		for {
+			var p = atomic.LoadInt32(&peak)
+
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&active, -1)
+
+		if r.URL.Path == "/books/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		var body, _ = ioutil.ReadAll(r.Body)
+		fmt.Fprintf(w, `{"method":%q,"path":%q,"body":%q}`, r.Method, r.URL.Path, body)
+	}))
+	defer s.Close()
+
+	var b = NewHTTPClient(WithBaseURL(s.URL)).Batch().Concurrency(2)
+
+	b.Create("books", map[string]string{"title": "Hamlet"}).
+		Update("books", "1", map[string]string{"title": "Macbeth"}).
+		Replace("books", "2", map[string]string{"title": "Othello"}).
+		Delete("books", "missing").
+		Query("books", query.Filter("title", "Hamlet").Limit(1)).
+		Create("books", func() {})
+
+	if b.Len() != 6 {
+		t.Errorf("Expected 6 operations, got %d instead", b.Len())
+	}
+
+	var results, err = b.Send(context.Background())
+
+	var wantErr = BatchError{Failed: []int{3, 5}, Total: 6}
+
+	if !reflect.DeepEqual(err, wantErr) {
+		t.Errorf("Expected error %v, got %v instead", wantErr, err)
+	}
+
+	if err.Error() != "2 of 6 batch operations failed" {
+		t.Errorf("Unexpected error message %v", err)
+	}
+
+	var want = []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{"POST", "/books", `{"title":"Hamlet"}`},
+		{"PATCH", "/books/1", `{"title":"Macbeth"}`},
+		{"PUT", "/books/2", `{"title":"Othello"}`},
+		{"DELETE", "/books/missing", ""},
+		{"GET", "/books", `{"filter":[{"title":{"operator":"=","value":"Hamlet"}}],"limit":1}`},
+	}
+
+	for n, w := range want {
+		var r = results[n]
+
+		if r.Method != w.method {
+			t.Errorf("Expected method %v for result %d, got %v instead", w.method, n, r.Method)
+		}
+
+		if n == 3 {
+			if r.Err != (StatusError{http.StatusNotFound}) {
+				t.Errorf("Expected not found error, got %v instead", r.Err)
+			}
+
+			continue
+		}
+
+		var got map[string]string
+
+		if err := r.Request.DecodeJSON(&got); err != nil {
+			t.Error(err)
+		}
+
+		if got["method"] != w.method || got["path"] != w.path || got["body"] != w.body {
+			t.Errorf("Expected result %d to be %v, got %v instead", n, w, got)
+		}
+	}
+
+	if results[5].Err == nil || results[5].Request.Request != nil {
+		t.Errorf("Expected marshal error not to send the request")
+	}
+
+	if peak > 2 {
+		t.Errorf("Expected at most 2 operations in parallel, got %d", peak)
+	}
+}
+
+func TestBatchLeakDetector(t *testing.T) {
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/books/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		fmt.Fprintf(w, `{"path":%q}`, r.URL.Path)
+	}))
+	defer s.Close()
+
+	var d = NewLeakDetector()
+	var b = NewHTTPClient(WithBaseURL(s.URL), WithLeakDetector(d)).Batch()
+
+	for i := 0; i < 10; i++ {
+		b.Create("books", map[string]int{"n": i})
+	}
+
+	b.Delete("books", "missing")
+
+	var results, err = b.Send(context.Background())
+
+	if want := (BatchError{Failed: []int{10}, Total: 11}); !reflect.DeepEqual(err, want) {
+		t.Errorf("Expected error %v, got %v instead", want, err)
+	}
+
+	if open := d.Open(); len(open) != 0 {
+		t.Errorf("Expected no response bodies open, got %v instead", open)
+	}
+
+	var got map[string]string
+
+	if err := results[0].Request.DecodeJSON(&got); err != nil || got["path"] != "/books" {
+		t.Errorf("Expected response body to be available, got %v (%v) instead", got, err)
+	}
+}
+
+func TestBatchMaxResponseSize(t *testing.T) {
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		fmt.Fprintf(w, `{"path":%q}`, r.URL.Path)
+	}))
+	defer s.Close()
+
+	var results, err = NewHTTPClient(WithBaseURL(s.URL), WithMaxResponseSize(5)).
+		Batch().
+		Delete("books", "1").
+		Send(context.Background())
+
+	if want := (BatchError{Failed: []int{0}, Total: 1}); !reflect.DeepEqual(err, want) {
+		t.Errorf("Expected error %v, got %v instead", want, err)
+	}
+
+	if results[0].Err != (ErrResponseTooLarge{5}) {
+		t.Errorf("Expected error %v, got %v instead", ErrResponseTooLarge{5}, results[0].Err)
+	}
+}
+
+func TestBatchContextCanceled(t *testing.T) {
+	var requests int32
+
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+	}))
+	defer s.Close()
+
+	var ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+
+	var b = NewHTTPClient().Batch()
+
+	for i := 0; i < 3; i++ {
+		b.Add("GET", URL(s.URL))
+	}
+
+	var results, err = b.Send(ctx)
+
+	if be, ok := err.(BatchError); !ok || len(be.Failed) != 3 {
+		t.Errorf("Expected all operations to fail, got %v instead", err)
+	}
+
+	for _, r := range results {
+		if r.Err == nil {
+			t.Errorf("Expected context error")
+		}
+	}
+
+	if requests != 0 {
+		t.Errorf("Expected no requests to be sent, got %d", requests)
+	}
+}
+
+func TestBatchEmpty(t *testing.T) {
+	var results, err = NewBatch().Concurrency(0).Send(context.Background())
+
+	if len(results) != 0 || err != nil {
+		t.Errorf("Expected empty batch to succeed, got %v (%v)", results, err)
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wedeploy
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// ErrResponseTooLarge is returned when a response body is larger than the
+// maximum response size of the request or client
+type ErrResponseTooLarge struct {
+	Limit int64
+}
+
+func (e ErrResponseTooLarge) Error() string {
+	return fmt.Sprintf("response body larger than %d bytes", e.Limit)
+}
+
+// maxErrorBodySize is the maximum size of an error response body read
+// when there is no maximum response size
+const maxErrorBodySize = 1 << 20
+
+// WithMaxResponseSize limits the size of the response bodies read,
+// in bytes. Reading more fails with ErrResponseTooLarge.
+func WithMaxResponseSize(n int64) Option {
+	return func(h *HTTPClient) {
+		h.maxResponseSize = n
+	}
+}
+
+// MaxResponseSize limits the size of the response body read, in bytes,
+// overriding the maximum response size of the client
+func (w *WeDeploy) MaxResponseSize(n int64) *WeDeploy {
+	w.maxResponseSize = n
+	return w
+}
+
+func (w *WeDeploy) getMaxResponseSize() int64 {
+	if w.maxResponseSize > 0 {
+		return w.maxResponseSize
+	}
+
+	return w.getClient().maxResponseSize
+}
+
+// handleResponseBody limits the response body, tracks it on the leak
+// detector, and reads error responses into memory, closing their body
+// so that the connection is released even if the caller doesn't close it.
+func (w *WeDeploy) handleResponseBody() error {
+	var limit = w.getMaxResponseSize()
+	var resp = w.Response
+
+	if limit > 0 && resp.ContentLength > limit && w.Request.Method != "HEAD" {
+		resp.Body.Close()
+		resp.Body = http.NoBody
+		return ErrResponseTooLarge{limit}
+	}
+
+	if limit > 0 {
+		resp.Body = &limitedBody{rc: resp.Body, n: limit, limit: limit}
+	}
+
+	if d := w.getClient().leaks; d != nil {
+		resp.Body = d.track(w.Request.Method+" "+w.Request.URL.String(), resp.Body)
+	}
+
+	if resp.StatusCode < 400 {
+		return nil
+	}
+
+	if limit <= 0 {
+		limit = maxErrorBodySize
+	}
+
+	var bin, _ = ioutil.ReadAll(io.LimitReader(resp.Body, limit))
+
+	// drain what is left of small bodies so the connection can be reused
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, maxErrorBodySize))
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(bin))
+	return StatusError{resp.StatusCode}
+}
+
+// limitedBody fails with ErrResponseTooLarge when more than limit bytes
+// are available
+type limitedBody struct {
+	rc    io.ReadCloser
+	n     int64
+	limit int64
+}
+
+func (l *limitedBody) Read(p []byte) (int, error) {
+	if l.n <= 0 {
+		var b [1]byte
+		var n, err = l.rc.Read(b[:])
+
+		if n != 0 {
+			return 0, ErrResponseTooLarge{l.limit}
+		}
+
+		return 0, err
+	}
+
+	if int64(len(p)) > l.n {
+		p = p[:l.n]
+	}
+
+	var n, err = l.rc.Read(p)
+	l.n -= int64(n)
+	return n, err
+}
+
+func (l *limitedBody) Close() error {
+	return l.rc.Close()
+}
+
+// LeakDetector tracks response bodies that are never closed.
+// It is meant to be used on tests:
+//
+//	var d = wedeploy.NewLeakDetector()
+//	var client = wedeploy.NewHTTPClient(wedeploy.WithLeakDetector(d))
+//	// ...
+//	if open := d.Open(); len(open) != 0 {
+//		t.Errorf("Response bodies not closed: %v", open)
+//	}
+type LeakDetector struct {
+	mutex  sync.Mutex
+	bodies map[*trackedBody]bool
+}
+
+// NewLeakDetector creates a leak detector
+func NewLeakDetector() *LeakDetector {
+	return &LeakDetector{
+		bodies: map[*trackedBody]bool{},
+	}
+}
+
+// WithLeakDetector tracks the response bodies of the client on the detector
+func WithLeakDetector(d *LeakDetector) Option {
+	return func(h *HTTPClient) {
+		h.leaks = d
+	}
+}
+
+// Open gets the method and URL of the requests whose response body
+// wasn't closed yet
+func (d *LeakDetector) Open() []string {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	var open = []string{}
+
+	for b := range d.bodies {
+		open = append(open, b.request)
+	}
+
+	sort.Strings(open)
+	return open
+}
+
+func (d *LeakDetector) track(request string, rc io.ReadCloser) io.ReadCloser {
+	var b = &trackedBody{ReadCloser: rc, request: request, detector: d}
+
+	d.mutex.Lock()
+	d.bodies[b] = true
+	d.mutex.Unlock()
+
+	return b
+}
+
+type trackedBody struct {
+	io.ReadCloser
+	request  string
+	detector *LeakDetector
+}
+
+func (t *trackedBody) Close() error {
+	t.detector.mutex.Lock()
+	delete(t.detector.bodies, t)
+	t.detector.mutex.Unlock()
+
+	return t.ReadCloser.Close()
+}
@@ -0,0 +1,209 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wedeploy
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func setupBodyServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body = `["` + strings.Repeat("a", 100) + `"]`
+
+		switch r.URL.Path {
+		case "/chunked":
+			// flushing before writing the body prevents a Content-Length
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush()
+		case "/error":
+			w.WriteHeader(http.StatusInternalServerError)
+			body = `{"message":"failure"}`
+		}
+
+		w.Write([]byte(body))
+	}))
+}
+
+func TestMaxResponseSizeContentLength(t *testing.T) {
+	var s = setupBodyServer()
+	defer s.Close()
+
+	var client = NewHTTPClient(WithMaxResponseSize(50))
+	var req = client.URL(s.URL)
+	var err = req.Get()
+
+	if want := (ErrResponseTooLarge{50}); err != want {
+		t.Errorf("Expected error %v, got %v instead", want, err)
+	}
+
+	assertTextualBody(t, "", req.Response.Body)
+}
+
+func TestMaxResponseSizeChunked(t *testing.T) {
+	var s = setupBodyServer()
+	defer s.Close()
+
+	var client = NewHTTPClient(WithMaxResponseSize(50))
+	var req = client.URL(s.URL, "chunked")
+
+	if err := req.Get(); err != nil {
+		t.Fatal(err)
+	}
+
+	var v []string
+
+	if err := req.DecodeJSON(&v); err != (ErrResponseTooLarge{50}) {
+		t.Errorf("Expected error %v, got %v instead", ErrResponseTooLarge{50}, err)
+	}
+}
+
+func TestMaxResponseSizeExact(t *testing.T) {
+	var s = setupBodyServer()
+	defer s.Close()
+
+	var client = NewHTTPClient(WithMaxResponseSize(104))
+
+	for _, path := range []string{"", "chunked"} {
+		var req = client.URL(s.URL, path)
+
+		if err := req.Get(); err != nil {
+			t.Fatal(err)
+		}
+
+		var v []string
+
+		if err := req.DecodeJSON(&v); err != nil {
+			t.Errorf("Expected no error decoding %v, got %v instead", path, err)
+		}
+
+		if len(v) != 1 || len(v[0]) != 100 {
+			t.Errorf("Expected body to be read, got %v instead", v)
+		}
+	}
+}
+
+func TestMaxResponseSizeRequest(t *testing.T) {
+	var s = setupBodyServer()
+	defer s.Close()
+
+	var client = NewHTTPClient(WithMaxResponseSize(50))
+	var req = client.URL(s.URL).MaxResponseSize(1000)
+
+	if err := req.Get(); err != nil {
+		t.Errorf("Expected request limit to override client limit, got %v instead", err)
+	}
+
+	req.Response.Body.Close()
+
+	var c = req.Clone()
+
+	if c.maxResponseSize != 1000 {
+		t.Errorf("Expected clone to keep the maximum response size, got %v instead", c.maxResponseSize)
+	}
+
+	req = URL(s.URL).MaxResponseSize(10)
+
+	if err := req.Get(); err != (ErrResponseTooLarge{10}) {
+		t.Errorf("Expected error %v, got %v instead", ErrResponseTooLarge{10}, err)
+	}
+}
+
+func TestErrorResponseBodyClosed(t *testing.T) {
+	var s = setupBodyServer()
+	defer s.Close()
+
+	var d = NewLeakDetector()
+	var client = NewHTTPClient(WithLeakDetector(d))
+	var req = client.URL(s.URL, "error")
+	var err = req.Get()
+
+	if err != (StatusError{500}) {
+		t.Errorf("Expected status error, got %v instead", err)
+	}
+
+	if open := d.Open(); len(open) != 0 {
+		t.Errorf("Expected error response body to be closed, got %v open", open)
+	}
+
+	var bin, _ = ioutil.ReadAll(req.Response.Body)
+
+	if string(bin) != `{"message":"failure"}` {
+		t.Errorf("Expected error body to be readable, got %s instead", bin)
+	}
+}
+
+func TestErrorResponseBodyTruncated(t *testing.T) {
+	var s = setupBodyServer()
+	defer s.Close()
+
+	var client = NewHTTPClient(WithMaxResponseSize(10))
+	var req = client.URL(s.URL, "error")
+
+	if err := req.Get(); err != (ErrResponseTooLarge{10}) {
+		t.Errorf("Expected error %v, got %v instead", ErrResponseTooLarge{10}, err)
+	}
+
+	req = client.URL(s.URL, "error").MaxResponseSize(30)
+
+	if err := req.Get(); err != (StatusError{500}) {
+		t.Errorf("Expected status error, got %v instead", err)
+	}
+
+	assertTextualBody(t, `{"message":"failure"}`, req.Response.Body)
+}
+
+func TestLeakDetector(t *testing.T) {
+	var s = setupBodyServer()
+	defer s.Close()
+
+	var d = NewLeakDetector()
+	var client = NewHTTPClient(WithLeakDetector(d))
+
+	var leaked = client.URL(s.URL, "leaked")
+
+	if err := leaked.Get(); err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded = client.URL(s.URL, "decoded")
+
+	if err := decoded.Get(); err != nil {
+		t.Fatal(err)
+	}
+
+	var v []string
+
+	if err := decoded.DecodeJSON(&v); err != nil {
+		t.Error(err)
+	}
+
+	var streamed = client.URL(s.URL, "streamed")
+
+	if err := streamed.Get(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := streamed.Stream(func(json.RawMessage) error { return ErrStopStream }); err != nil {
+		t.Error(err)
+	}
+
+	var want = []string{"GET " + s.URL + "/leaked"}
+
+	if open := d.Open(); !reflect.DeepEqual(open, want) {
+		t.Errorf("Expected open bodies %v, got %v instead", want, open)
+	}
+
+	leaked.Response.Body.Close()
+
+	if open := d.Open(); len(open) != 0 {
+		t.Errorf("Expected no open bodies, got %v instead", open)
+	}
+}
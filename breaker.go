@@ -0,0 +1,296 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wedeploy
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// CircuitState is the state of a circuit breaker
+type CircuitState int
+
+const (
+	// CircuitClosed lets requests through
+	CircuitClosed CircuitState = iota
+
+	// CircuitOpen rejects requests with ErrCircuitOpen
+	CircuitOpen
+
+	// CircuitHalfOpen lets a limited number of trial requests through
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+
+	return fmt.Sprintf("CircuitState(%d)", int(s))
+}
+
+// ErrCircuitOpen is returned for requests rejected by an open circuit
+type ErrCircuitOpen struct {
+	Key     string
+	RetryAt time.Time
+}
+
+func (e ErrCircuitOpen) Error() string {
+	return fmt.Sprintf("circuit breaker open for %v", e.Key)
+}
+
+// BreakerSettings for a circuit breaker
+type BreakerSettings struct {
+	// FailureThreshold is the number of consecutive failures that opens
+	// a circuit (default 5)
+	FailureThreshold int
+
+	// OpenTimeout is how long a circuit stays open before letting
+	// trial requests through (default 30s)
+	OpenTimeout time.Duration
+
+	// HalfOpenRequests is the number of successful trial requests
+	// needed to close a half-open circuit (default 1)
+	HalfOpenRequests int
+
+	// FailureCodes are the status codes counted as failures
+	// (default 429, 500, 502, 503, and 504).
+	// Transport errors are always counted as failures.
+	FailureCodes []int
+
+	// Key of the circuit of a request (default the URL host)
+	Key func(*http.Request) string
+
+	// OnStateChange is called when the state of a circuit changes
+	OnStateChange func(key string, from, to CircuitState)
+}
+
+// DefaultFailureCodes are the status codes counted as failures by default
+var DefaultFailureCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// WithCircuitBreaker rejects the requests to a host or service with
+// ErrCircuitOpen, without sending them, after consecutive failures
+func WithCircuitBreaker(s BreakerSettings) Option {
+	if s.FailureThreshold <= 0 {
+		s.FailureThreshold = 5
+	}
+
+	if s.OpenTimeout <= 0 {
+		s.OpenTimeout = 30 * time.Second
+	}
+
+	if s.HalfOpenRequests <= 0 {
+		s.HalfOpenRequests = 1
+	}
+
+	if s.FailureCodes == nil {
+		s.FailureCodes = DefaultFailureCodes
+	}
+
+	if s.Key == nil {
+		s.Key = func(req *http.Request) string {
+			return req.URL.Host
+		}
+	}
+
+	return func(h *HTTPClient) {
+		h.breaker = &circuitBreaker{
+			settings: s,
+			circuits: map[string]*circuit{},
+			now:      time.Now,
+		}
+	}
+}
+
+// CircuitState gets the state of the circuit with the given key.
+// It is CircuitClosed if the client has no circuit breaker.
+func (h *HTTPClient) CircuitState(key string) CircuitState {
+	if h.breaker == nil {
+		return CircuitClosed
+	}
+
+	return h.breaker.state(key)
+}
+
+type circuit struct {
+	state     CircuitState
+	failures  int
+	successes int
+	trials    int
+	openedAt  time.Time
+}
+
+type circuitBreaker struct {
+	settings BreakerSettings
+	mutex    sync.Mutex
+	circuits map[string]*circuit
+	now      func() time.Time
+}
+
+type stateChange struct {
+	key      string
+	from, to CircuitState
+}
+
+type outcome int
+
+const (
+	outcomeSuccess outcome = iota
+	outcomeFailure
+	outcomeIgnored
+)
+
+func (b *circuitBreaker) wrap(next roundTrip) roundTrip {
+	return func(req *http.Request) (*http.Response, error) {
+		var key = b.settings.Key(req)
+
+		if err := b.allow(key); err != nil {
+			return nil, err
+		}
+
+		var resp, err = next(req)
+		b.record(key, b.outcome(req, resp, err))
+		return resp, err
+	}
+}
+
+func (b *circuitBreaker) outcome(req *http.Request, resp *http.Response, err error) outcome {
+	if err != nil {
+		if req.Context().Err() == context.Canceled {
+			return outcomeIgnored
+		}
+
+		return outcomeFailure
+	}
+
+	for _, code := range b.settings.FailureCodes {
+		if resp.StatusCode == code {
+			return outcomeFailure
+		}
+	}
+
+	return outcomeSuccess
+}
+
+func (b *circuitBreaker) state(key string) CircuitState {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if c, ok := b.circuits[key]; ok {
+		return c.state
+	}
+
+	return CircuitClosed
+}
+
+func (b *circuitBreaker) allow(key string) error {
+	b.mutex.Lock()
+
+	var c = b.circuits[key]
+
+	if c == nil {
+		c = &circuit{}
+		b.circuits[key] = c
+	}
+
+	var change *stateChange
+	var err error
+
+	if c.state == CircuitOpen {
+		var retryAt = c.openedAt.Add(b.settings.OpenTimeout)
+
+		if b.now().Before(retryAt) {
+			err = ErrCircuitOpen{Key: key, RetryAt: retryAt}
+		} else {
+			change = b.transition(key, c, CircuitHalfOpen)
+		}
+	}
+
+	if c.state == CircuitHalfOpen && err == nil {
+		if c.trials >= b.settings.HalfOpenRequests {
+			err = ErrCircuitOpen{Key: key, RetryAt: b.now()}
+		} else {
+			c.trials++
+		}
+	}
+
+	b.mutex.Unlock()
+	b.notify(change)
+	return err
+}
+
+func (b *circuitBreaker) record(key string, o outcome) {
+	b.mutex.Lock()
+
+	var c = b.circuits[key]
+	var change *stateChange
+
+	switch c.state {
+	case CircuitClosed:
+		switch o {
+		case outcomeFailure:
+			c.failures++
+
+			if c.failures >= b.settings.FailureThreshold {
+				change = b.transition(key, c, CircuitOpen)
+			}
+		case outcomeSuccess:
+			c.failures = 0
+		}
+	case CircuitHalfOpen:
+		if c.trials > 0 {
+			c.trials--
+		}
+
+		switch o {
+		case outcomeFailure:
+			change = b.transition(key, c, CircuitOpen)
+		case outcomeSuccess:
+			c.successes++
+
+			if c.successes >= b.settings.HalfOpenRequests {
+				change = b.transition(key, c, CircuitClosed)
+			}
+		}
+	}
+
+	b.mutex.Unlock()
+	b.notify(change)
+}
+
+func (b *circuitBreaker) transition(key string, c *circuit, to CircuitState) *stateChange {
+	var change = &stateChange{key, c.state, to}
+
+	c.state = to
+	c.failures = 0
+	c.successes = 0
+	c.trials = 0
+
+	if to == CircuitOpen {
+		c.openedAt = b.now()
+	}
+
+	return change
+}
+
+func (b *circuitBreaker) notify(change *stateChange) {
+	if change != nil && b.settings.OnStateChange != nil {
+		b.settings.OnStateChange(change.key, change.from, change.to)
+	}
+}
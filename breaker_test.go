@@ -0,0 +1,154 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wedeploy
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	var status int32 = http.StatusServiceUnavailable
+	var requests int32
+
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(int(atomic.LoadInt32(&status)))
+	}))
+	defer s.Close()
+
+	var changes []string
+
+	var hc = NewHTTPClient(WithCircuitBreaker(BreakerSettings{
+		FailureThreshold: 2,
+		OpenTimeout:      time.Minute,
+		OnStateChange: func(key string, from, to CircuitState) {
+			changes = append(changes, from.String()+" -> "+to.String())
+		},
+	}))
+
+	var clock = &fakeClock{time.Now()}
+	hc.breaker.now = clock.now
+
+	var u, _ = url.Parse(s.URL)
+
+	for i := 0; i < 2; i++ {
+		if err := hc.URL(s.URL).Get(); err != (StatusError{http.StatusServiceUnavailable}) {
+			t.Errorf("Expected status error, got %v instead", err)
+		}
+	}
+
+	if state := hc.CircuitState(u.Host); state != CircuitOpen {
+		t.Errorf("Expected circuit to be open, got %v instead", state)
+	}
+
+	var err = hc.URL(s.URL).Get()
+	var want = ErrCircuitOpen{Key: u.Host, RetryAt: clock.t.Add(time.Minute)}
+
+	if err != want {
+		t.Errorf("Expected error %v, got %v instead", want, err)
+	}
+
+	if requests != 2 {
+		t.Errorf("Expected open circuit not to send requests, got %d requests", requests)
+	}
+
+	clock.t = clock.t.Add(time.Minute)
+
+	if err := hc.URL(s.URL).Get(); err == nil {
+		t.Errorf("Expected failed trial request")
+	}
+
+	if state := hc.CircuitState(u.Host); state != CircuitOpen {
+		t.Errorf("Expected circuit to be open again, got %v instead", state)
+	}
+
+	clock.t = clock.t.Add(time.Minute)
+	atomic.StoreInt32(&status, http.StatusOK)
+
+	if err := hc.URL(s.URL).Get(); err != nil {
+		t.Error(err)
+	}
+
+	if state := hc.CircuitState(u.Host); state != CircuitClosed {
+		t.Errorf("Expected circuit to be closed, got %v instead", state)
+	}
+
+	var wantChanges = []string{
+		"closed -> open",
+		"open -> half-open",
+		"half-open -> open",
+		"open -> half-open",
+		"half-open -> closed",
+	}
+
+	if !reflect.DeepEqual(changes, wantChanges) {
+		t.Errorf("Expected state changes %v, got %v instead", wantChanges, changes)
+	}
+}
+
+func TestCircuitBreakerIgnoresClientErrors(t *testing.T) {
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer s.Close()
+
+	var hc = NewHTTPClient(WithCircuitBreaker(BreakerSettings{FailureThreshold: 1}))
+
+	for i := 0; i < 3; i++ {
+		if err := hc.URL(s.URL).Get(); err != (StatusError{http.StatusNotFound}) {
+			t.Errorf("Expected status error, got %v instead", err)
+		}
+	}
+}
+
+func TestCircuitBreakerTransportErrors(t *testing.T) {
+	var hc = NewHTTPClient(
+		WithHTTP(&http.Client{Transport: failingTransport{}}),
+		WithCircuitBreaker(BreakerSettings{
+			FailureThreshold: 2,
+			Key: func(req *http.Request) string {
+				return "data"
+			},
+		}))
+
+	for i := 0; i < 2; i++ {
+		if err := hc.URL("http://example.com/").Get(); err == nil {
+			t.Errorf("Expected transport error")
+		}
+	}
+
+	if _, ok := hc.URL("http://example.net/").Get().(ErrCircuitOpen); !ok {
+		t.Errorf("Expected circuit to be open for all hosts with the same key")
+	}
+
+	if state := hc.CircuitState("data"); state != CircuitOpen {
+		t.Errorf("Expected circuit to be open, got %v instead", state)
+	}
+}
+
+func TestCircuitStateWithoutBreaker(t *testing.T) {
+	if state := NewHTTPClient().CircuitState("example.com"); state != CircuitClosed {
+		t.Errorf("Expected circuit to be closed, got %v instead", state)
+	}
+}
+
+func TestCircuitStateString(t *testing.T) {
+	if s := CircuitState(7).String(); s != "CircuitState(7)" {
+		t.Errorf("Unexpected string %v", s)
+	}
+}
+
+type failingTransport struct{}
+
+func (failingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return nil, errors.New("connection refused")
+}
@@ -0,0 +1,256 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package bulk exports Data collections to NDJSON or CSV files
+// and imports them back.
+package bulk
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Format of the exported files
+type Format string
+
+const (
+	// NDJSON writes a JSON document per line
+	NDJSON Format = "ndjson"
+
+	// CSV writes a header line with the fields and a line per document.
+	// String values are written as is, and other values as JSON.
+	// Strings that would be read back as JSON, such as "123",
+	// are written as JSON strings.
+	CSV Format = "csv"
+)
+
+// FormatFromPath gets the format from the file extension, defaulting to NDJSON
+func FormatFromPath(path string) Format {
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return CSV
+	}
+
+	return NDJSON
+}
+
+// Document of a collection
+type Document map[string]interface{}
+
+// encoder writes documents on a format
+type encoder interface {
+	Encode(Document) error
+	Flush() error
+}
+
+func newEncoder(w io.Writer, format Format, fields []string) (encoder, error) {
+	switch format {
+	case NDJSON, "":
+		return &ndjsonEncoder{json.NewEncoder(w)}, nil
+	case CSV:
+		return &csvEncoder{w: csv.NewWriter(w), fields: fields, strict: len(fields) == 0}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported format %q", format)
+}
+
+type ndjsonEncoder struct {
+	e *json.Encoder
+}
+
+func (n *ndjsonEncoder) Encode(d Document) error {
+	return n.e.Encode(d)
+}
+
+func (n *ndjsonEncoder) Flush() error {
+	return nil
+}
+
+// ColumnError is returned on CSV export when the columns are the fields
+// of the first document and another document has a field that isn't one
+// of them, which would be lost. Set the fields to export to avoid it.
+type ColumnError struct {
+	Field string
+}
+
+func (c ColumnError) Error() string {
+	return fmt.Sprintf("field %q isn't a CSV column: set the fields to export", c.Field)
+}
+
+type csvEncoder struct {
+	w      *csv.Writer
+	fields []string
+	header bool
+
+	// strict rejects documents with fields that aren't columns
+	strict bool
+}
+
+func (c *csvEncoder) Encode(d Document) error {
+	if !c.header {
+		if len(c.fields) == 0 {
+			c.fields = documentFields(d)
+		}
+
+		if err := c.w.Write(c.fields); err != nil {
+			return err
+		}
+
+		c.header = true
+	}
+
+	if c.strict {
+		if err := c.checkColumns(d); err != nil {
+			return err
+		}
+	}
+
+	var record = make([]string, len(c.fields))
+
+	for i, field := range c.fields {
+		var v, ok = d[field]
+
+		if !ok || v == nil {
+			continue
+		}
+
+		if s, ok := v.(string); ok && csvValue(s) == s {
+			record[i] = s
+			continue
+		}
+
+		var bin, err = json.Marshal(v)
+
+		if err != nil {
+			return err
+		}
+
+		record[i] = string(bin)
+	}
+
+	return c.w.Write(record)
+}
+
+func (c *csvEncoder) checkColumns(d Document) error {
+	for _, field := range documentFields(d) {
+		var found bool
+
+		for _, f := range c.fields {
+			if f == field {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return ColumnError{field}
+		}
+	}
+
+	return nil
+}
+
+func (c *csvEncoder) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// documentFields sorted, with id first
+func documentFields(d Document) []string {
+	var fields []string
+
+	for field := range d {
+		if field != "id" {
+			fields = append(fields, field)
+		}
+	}
+
+	sort.Strings(fields)
+
+	if _, ok := d["id"]; ok {
+		fields = append([]string{"id"}, fields...)
+	}
+
+	return fields
+}
+
+// decoder reads documents on a format
+type decoder interface {
+	// Decode the next document, returning io.EOF at the end
+	Decode() (Document, error)
+}
+
+func newDecoder(r io.Reader, format Format) (decoder, error) {
+	switch format {
+	case NDJSON, "":
+		var d = json.NewDecoder(r)
+		d.UseNumber()
+		return &ndjsonDecoder{d}, nil
+	case CSV:
+		return &csvDecoder{r: csv.NewReader(r)}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported format %q", format)
+}
+
+type ndjsonDecoder struct {
+	d *json.Decoder
+}
+
+func (n *ndjsonDecoder) Decode() (Document, error) {
+	var d Document
+	var err = n.d.Decode(&d)
+	return d, err
+}
+
+type csvDecoder struct {
+	r      *csv.Reader
+	fields []string
+}
+
+func (c *csvDecoder) Decode() (Document, error) {
+	if c.fields == nil {
+		var header, err = c.r.Read()
+
+		if err != nil {
+			return nil, err
+		}
+
+		c.fields = header
+	}
+
+	var record, err = c.r.Read()
+
+	if err != nil {
+		return nil, err
+	}
+
+	var d = Document{}
+
+	for i, field := range c.fields {
+		if record[i] != "" {
+			d[field] = csvValue(record[i])
+		}
+	}
+
+	return d, nil
+}
+
+// csvValue decodes a value written as JSON, or else keeps it as a string
+func csvValue(s string) interface{} {
+	var v interface{}
+	var d = json.NewDecoder(bytes.NewReader([]byte(s)))
+	d.UseNumber()
+
+	if err := d.Decode(&v); err != nil || d.More() {
+		return s
+	}
+
+	return v
+}
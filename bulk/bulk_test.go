@@ -0,0 +1,167 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bulk
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/henvic/wedeploy-sdk-go"
+	"github.com/henvic/wedeploy-sdk-go/emulator"
+)
+
+func setupEmulator() (*emulator.Emulator, *httptest.Server, *wedeploy.HTTPClient) {
+	var e = emulator.New()
+	var s = httptest.NewServer(e)
+	return e, s, wedeploy.NewHTTPClient(wedeploy.WithBaseURL(s.URL))
+}
+
+func TestFormatFromPath(t *testing.T) {
+	var cases = map[string]Format{
+		"books.csv":    CSV,
+		"books.CSV":    CSV,
+		"books.ndjson": NDJSON,
+		"books.json":   NDJSON,
+		"books":        NDJSON,
+	}
+
+	for path, want := range cases {
+		if got := FormatFromPath(path); got != want {
+			t.Errorf("Expected format %v for %v, got %v instead", want, path, got)
+		}
+	}
+}
+
+func TestCSVRoundTrip(t *testing.T) {
+	var docs = []Document{
+		{"id": "1", "title": "Hamlet", "year": json.Number("1603"),
+			"tags": []interface{}{"tragedy"}, "published": true},
+		{"id": "2", "title": "123", "year": json.Number("1623")},
+	}
+
+	var b bytes.Buffer
+	var e, err = newEncoder(&b, CSV, nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, d := range docs {
+		if err := e.Encode(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := e.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	var want = "id,published,tags,title,year\n" +
+		"\"\"\"1\"\"\",true,\"[\"\"tragedy\"\"]\",Hamlet,1603\n" +
+		"\"\"\"2\"\"\",,,\"\"\"123\"\"\",1623\n"
+
+	if b.String() != want {
+		t.Errorf("Expected CSV %q, got %q instead", want, b.String())
+	}
+
+	d, err := newDecoder(&b, CSV)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []Document
+
+	for {
+		var doc, err = d.Decode()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got = append(got, doc)
+	}
+
+	if !reflect.DeepEqual(got, docs) {
+		t.Errorf("Expected documents %v, got %v instead", docs, got)
+	}
+}
+
+func TestCSVColumnError(t *testing.T) {
+	var docs = []Document{
+		{"id": "1", "title": "Hamlet"},
+		{"id": "2", "year": json.Number("1623")},
+	}
+
+	var b bytes.Buffer
+	var e, err = newEncoder(&b, CSV, nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := e.Encode(docs[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := e.Encode(docs[1]); err != (ColumnError{"year"}) {
+		t.Errorf("Expected column error, got %v instead", err)
+	}
+
+	b.Reset()
+
+	if e, err = newEncoder(&b, CSV, []string{"id", "title"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, d := range docs {
+		if err := e.Encode(d); err != nil {
+			t.Errorf("Expected fields outside the columns to be ignored, got %v instead", err)
+		}
+	}
+}
+
+func TestCSVValue(t *testing.T) {
+	var cases = map[string]interface{}{
+		"Hamlet":         "Hamlet",
+		`"quoted"`:       "quoted",
+		"1.5":            json.Number("1.5"),
+		"false":          false,
+		"null":           nil,
+		`{"a":1}`:        map[string]interface{}{"a": json.Number("1")},
+		"1 2":            "1 2",
+		"not json {}":    "not json {}",
+		"[1,2] [3]":      "[1,2] [3]",
+		"2017-01-01Z":    "2017-01-01Z",
+		" 7 ":            json.Number("7"),
+		"true story":     "true story",
+		"[\"a\", \"b\"]": []interface{}{"a", "b"},
+	}
+
+	for s, want := range cases {
+		if got := csvValue(s); !reflect.DeepEqual(got, want) {
+			t.Errorf("Expected %q to be %#v, got %#v instead", s, want, got)
+		}
+	}
+}
+
+func TestUnsupportedFormat(t *testing.T) {
+	if _, err := newEncoder(nil, "xml", nil); err == nil {
+		t.Errorf("Expected error")
+	}
+
+	if _, err := newDecoder(strings.NewReader(""), "xml"); err == nil {
+		t.Errorf("Expected error")
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bulk
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/henvic/wedeploy-sdk-go"
+	"github.com/henvic/wedeploy-sdk-go/query"
+)
+
+// DefaultPageSize is the number of documents fetched per request on export
+const DefaultPageSize = 1000
+
+// ExportOptions for exporting a collection
+type ExportOptions struct {
+	Collection string
+
+	// Query with the filters and sort of the documents exported.
+	// Its offset and limit are kept, and the documents are fetched in pages.
+	// Sort the documents by an unique field, such as id, for a stable export.
+	Query *query.Builder
+
+	Format Format
+
+	// Fields are the CSV columns, defaulting to the fields of the first
+	// document, sorted with id first. Without them, documents with other
+	// fields fail the export with a ColumnError.
+	Fields []string
+
+	// PageSize is the number of documents fetched per request
+	PageSize int
+}
+
+// Export the documents of a collection, returning how many were exported
+func Export(ctx context.Context, hc *wedeploy.HTTPClient, w io.Writer, o ExportOptions) (int, error) {
+	var e, err = newEncoder(w, o.Format, o.Fields)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if o.PageSize <= 0 {
+		o.PageSize = DefaultPageSize
+	}
+
+	var q = o.Query
+
+	if q == nil {
+		q = query.New()
+	}
+
+	var offset, remaining = 0, -1
+
+	if q.BOffset != nil {
+		offset = *q.BOffset
+	}
+
+	if q.BLimit != nil {
+		remaining = *q.BLimit
+	}
+
+	var exported int
+
+	for remaining != 0 {
+		var size = o.PageSize
+
+		if remaining > 0 && remaining < size {
+			size = remaining
+		}
+
+		var docs, err = fetch(ctx, hc, o.Collection, q.Clone().Offset(offset).Limit(size))
+
+		if err != nil {
+			return exported, err
+		}
+
+		for _, d := range docs {
+			if err := e.Encode(d); err != nil {
+				return exported, err
+			}
+
+			exported++
+		}
+
+		if len(docs) < size {
+			break
+		}
+
+		offset += len(docs)
+
+		if remaining > 0 {
+			remaining -= len(docs)
+		}
+	}
+
+	return exported, e.Flush()
+}
+
+func fetch(ctx context.Context, hc *wedeploy.HTTPClient, collection string, q *query.Builder) ([]Document, error) {
+	var req = hc.URL(collection)
+	req.Query = q
+	req.SetContext(ctx)
+
+	if err := req.Get(); err != nil {
+		closeResponse(req)
+		return nil, err
+	}
+
+	defer closeResponse(req)
+
+	var docs []Document
+	var d = json.NewDecoder(req.Response.Body)
+	d.UseNumber()
+
+	if err := d.Decode(&docs); err != nil {
+		return nil, err
+	}
+
+	return docs, nil
+}
+
+func closeResponse(req *wedeploy.WeDeploy) {
+	if req.Response != nil {
+		_ = req.Response.Body.Close()
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bulk
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/henvic/wedeploy-sdk-go"
+	"github.com/henvic/wedeploy-sdk-go/filter"
+	"github.com/henvic/wedeploy-sdk-go/query"
+)
+
+func seed(t *testing.T, hc *wedeploy.HTTPClient, n int) {
+	for i := 1; i <= n; i++ {
+		var body = bytes.NewBufferString(fmt.Sprintf(`{"id":"%02d","n":%d}`, i, i))
+
+		if err := hc.URL("items").Body(body).Post(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestExportNDJSON(t *testing.T) {
+	var e, s, hc = setupEmulator()
+	defer s.Close()
+
+	seed(t, hc, 7)
+	var requests = e.Requests()
+
+	var b bytes.Buffer
+	var n, err = Export(context.Background(), hc, &b, ExportOptions{
+		Collection: "items",
+		Query:      query.Filter(filter.Gt("n", 1)).Sort("id", "desc"),
+		PageSize:   2,
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n != 6 {
+		t.Errorf("Expected 6 documents exported, got %d instead", n)
+	}
+
+	var want = `{"id":"07","n":7}
+{"id":"06","n":6}
+{"id":"05","n":5}
+{"id":"04","n":4}
+{"id":"03","n":3}
+{"id":"02","n":2}
+`
+
+	if b.String() != want {
+		t.Errorf("Expected export %v, got %v instead", want, b.String())
+	}
+
+	if pages := e.Requests() - requests; pages != 4 {
+		t.Errorf("Expected 4 pages to be fetched, got %d instead", pages)
+	}
+}
+
+func TestExportCSVWithOffsetAndLimit(t *testing.T) {
+	var _, s, hc = setupEmulator()
+	defer s.Close()
+
+	seed(t, hc, 7)
+
+	var b bytes.Buffer
+	var n, err = Export(context.Background(), hc, &b, ExportOptions{
+		Collection: "items",
+		Query:      query.Sort("id").Offset(1).Limit(3),
+		Format:     CSV,
+		Fields:     []string{"n", "missing"},
+		PageSize:   2,
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var want = "n,missing\n2,\n3,\n4,\n"
+
+	if n != 3 || b.String() != want {
+		t.Errorf("Expected export %q, got %q (%d) instead", want, b.String(), n)
+	}
+}
+
+func TestExportError(t *testing.T) {
+	var e, s, hc = setupEmulator()
+	defer s.Close()
+
+	e.Fail(http.StatusInternalServerError)
+
+	var _, err = Export(context.Background(), hc, &bytes.Buffer{}, ExportOptions{Collection: "items"})
+
+	if err != (wedeploy.StatusError{Code: http.StatusInternalServerError}) {
+		t.Errorf("Expected status error, got %v instead", err)
+	}
+}
@@ -0,0 +1,285 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bulk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/henvic/wedeploy-sdk-go"
+)
+
+// ImportOptions for importing documents to a collection
+type ImportOptions struct {
+	Collection string
+	Format     Format
+
+	// BatchSize is the number of documents sent before saving the checkpoint
+	// (default 100)
+	BatchSize int
+
+	// Concurrency is the number of documents sent in parallel (default 4)
+	Concurrency int
+
+	// Retries of a document failing with a transport error or with
+	// a 429 or 5xx status code (default 3, or none if negative)
+	Retries int
+
+	// Backoff before the first retry, doubled for each new retry
+	// (default 500ms)
+	Backoff time.Duration
+
+	// Checkpoint is a file keeping the number of documents imported.
+	// An import with an existing checkpoint resumes after them.
+	// It is removed when the import finishes.
+	Checkpoint string
+}
+
+// ImportError of a document
+type ImportError struct {
+	// Record is the position of the document on the file, starting at 0
+	Record int
+	Err    error
+}
+
+func (i ImportError) Error() string {
+	return fmt.Sprintf("can't import record %d: %v", i.Record, i.Err)
+}
+
+type checkpoint struct {
+	Records int `json:"records"`
+}
+
+// Import documents to a collection, returning how many were imported.
+// Documents with an id replace existing documents with the same id,
+// so that retrying them is safe. Other documents are created with an
+// idempotency key, which is reused when they are retried.
+func Import(ctx context.Context, hc *wedeploy.HTTPClient, r io.Reader, o ImportOptions) (int, error) {
+	var d, err = newDecoder(r, o.Format)
+
+	if err != nil {
+		return 0, err
+	}
+
+	setImportDefaults(&o)
+
+	var skip int
+
+	if skip, err = readCheckpoint(o.Checkpoint); err != nil {
+		return 0, err
+	}
+
+	var record, imported int
+	var batch []Document
+
+	for {
+		var doc, err = d.Decode()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return imported, ImportError{record + len(batch), err}
+		}
+
+		if record < skip {
+			record++
+			continue
+		}
+
+		batch = append(batch, doc)
+
+		if len(batch) == o.BatchSize {
+			if err := importBatch(ctx, hc, o, record, batch); err != nil {
+				return imported, err
+			}
+
+			record += len(batch)
+			imported += len(batch)
+			batch = nil
+
+			if err := writeCheckpoint(o.Checkpoint, record); err != nil {
+				return imported, err
+			}
+		}
+	}
+
+	if err := importBatch(ctx, hc, o, record, batch); err != nil {
+		return imported, err
+	}
+
+	imported += len(batch)
+
+	if o.Checkpoint != "" {
+		if err := os.Remove(o.Checkpoint); err != nil && !os.IsNotExist(err) {
+			return imported, err
+		}
+	}
+
+	return imported, nil
+}
+
+func setImportDefaults(o *ImportOptions) {
+	if o.BatchSize <= 0 {
+		o.BatchSize = 100
+	}
+
+	if o.Concurrency <= 0 {
+		o.Concurrency = 4
+	}
+
+	if o.Retries < 0 {
+		o.Retries = 0
+	} else if o.Retries == 0 {
+		o.Retries = 3
+	}
+
+	if o.Backoff <= 0 {
+		o.Backoff = 500 * time.Millisecond
+	}
+}
+
+// importBatch of documents starting at the record position,
+// retrying the documents that fail with retryable errors
+func importBatch(ctx context.Context, hc *wedeploy.HTTPClient, o ImportOptions, record int, docs []Document) error {
+	var pending = make([]int, len(docs))
+
+	for i := range pending {
+		pending[i] = i
+	}
+
+	var keys = make([]string, len(docs))
+	var backoff = o.Backoff
+
+	for attempt := 0; len(pending) != 0; attempt++ {
+		if attempt != 0 {
+			if err := sleep(ctx, backoff); err != nil {
+				return err
+			}
+
+			backoff *= 2
+		}
+
+		var b = hc.Batch().Concurrency(o.Concurrency)
+
+		for _, i := range pending {
+			if id, ok := docs[i]["id"]; ok {
+				b.Replace(o.Collection, fmt.Sprint(id), docs[i])
+				continue
+			}
+
+			var bin, err = json.Marshal(docs[i])
+
+			if err != nil {
+				return ImportError{record + i, err}
+			}
+
+			var w = hc.URL(o.Collection).Idempotent().Body(bytes.NewBuffer(bin))
+			w.IdempotencyKey = keys[i]
+			b.Add("POST", w)
+		}
+
+		var results, _ = b.Send(ctx)
+		var failed []int
+
+		for n, r := range results {
+			closeResponse(r.Request)
+			keys[pending[n]] = r.Request.IdempotencyKey
+
+			if r.Err == nil {
+				continue
+			}
+
+			var i = pending[n]
+
+			if !retryable(ctx, r.Err) || attempt == o.Retries {
+				return ImportError{record + i, r.Err}
+			}
+
+			failed = append(failed, i)
+		}
+
+		pending = failed
+	}
+
+	return nil
+}
+
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	if se, ok := err.(wedeploy.StatusError); ok {
+		return se.Code == http.StatusTooManyRequests || se.Code >= 500
+	}
+
+	return true
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	var timer = time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func readCheckpoint(path string) (int, error) {
+	if path == "" {
+		return 0, nil
+	}
+
+	var bin, err = ioutil.ReadFile(path)
+
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	var c checkpoint
+
+	if err := json.Unmarshal(bin, &c); err != nil {
+		return 0, fmt.Errorf("can't parse checkpoint %v: %v", path, err)
+	}
+
+	return c.Records, nil
+}
+
+func writeCheckpoint(path string, records int) error {
+	if path == "" {
+		return nil
+	}
+
+	var bin, err = json.Marshal(checkpoint{records})
+
+	if err != nil {
+		return err
+	}
+
+	var tmp = filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+
+	if err := ioutil.WriteFile(tmp, bin, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
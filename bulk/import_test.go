@@ -0,0 +1,241 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bulk
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/henvic/wedeploy-sdk-go"
+)
+
+func ndjson(n int) string {
+	var b bytes.Buffer
+
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, `{"id":"%02d","n":%d}`+"\n", i, i)
+	}
+
+	return b.String()
+}
+
+func TestImport(t *testing.T) {
+	var e, s, hc = setupEmulator()
+	defer s.Close()
+
+	var n, err = Import(context.Background(), hc, strings.NewReader(ndjson(5)+`{"title":"no id"}`),
+		ImportOptions{Collection: "items", BatchSize: 2})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var docs = e.Documents("items")
+
+	if n != 6 || len(docs) != 6 {
+		t.Errorf("Expected 6 documents imported, got %d (%d stored)", n, len(docs))
+	}
+
+	// importing again replaces the documents with the same id
+	if _, err = Import(context.Background(), hc, strings.NewReader(ndjson(5)),
+		ImportOptions{Collection: "items"}); err != nil {
+		t.Error(err)
+	}
+
+	if len(e.Documents("items")) != 6 {
+		t.Errorf("Expected documents to be replaced")
+	}
+}
+
+func TestImportCSV(t *testing.T) {
+	var e, s, hc = setupEmulator()
+	defer s.Close()
+
+	var n, err = Import(context.Background(), hc, strings.NewReader("id,title,year\n1,Hamlet,1603\n"),
+		ImportOptions{Collection: "books", Format: CSV})
+
+	if err != nil || n != 1 {
+		t.Fatalf("Expected 1 document imported, got %d (%v)", n, err)
+	}
+
+	var doc = e.Documents("books")[0]
+
+	if doc["title"] != "Hamlet" || doc["year"] != 1603.0 {
+		t.Errorf("Unexpected document %v", doc)
+	}
+}
+
+func TestImportRetries(t *testing.T) {
+	var e, s, hc = setupEmulator()
+	defer s.Close()
+
+	e.Fail(http.StatusServiceUnavailable, http.StatusTooManyRequests)
+
+	var n, err = Import(context.Background(), hc, strings.NewReader(ndjson(3)),
+		ImportOptions{Collection: "items", Concurrency: 1, Backoff: time.Millisecond})
+
+	if err != nil || n != 3 || len(e.Documents("items")) != 3 {
+		t.Errorf("Expected 3 documents imported after retries, got %d (%v)", n, err)
+	}
+}
+
+func TestImportRetriesIdempotent(t *testing.T) {
+	var e, s, _ = setupEmulator()
+	defer s.Close()
+
+	var lost = &loseResponses{n: 2}
+	var hc = wedeploy.NewHTTPClient(
+		wedeploy.WithBaseURL(s.URL),
+		wedeploy.WithHTTP(&http.Client{Transport: lost}))
+
+	var n, err = Import(context.Background(), hc, strings.NewReader(`{"title":"Hamlet"}`+"\n"+`{"title":"Macbeth"}`),
+		ImportOptions{Collection: "books", Concurrency: 1, Backoff: time.Millisecond})
+
+	if err != nil || n != 2 {
+		t.Errorf("Expected 2 documents imported after retries, got %d (%v)", n, err)
+	}
+
+	if docs := e.Documents("books"); len(docs) != 2 {
+		t.Errorf("Expected retries not to create duplicates, got %v instead", docs)
+	}
+}
+
+func TestImportFailure(t *testing.T) {
+	var e, s, hc = setupEmulator()
+	defer s.Close()
+
+	e.Fail(http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+
+	var _, err = Import(context.Background(), hc, strings.NewReader(ndjson(1)),
+		ImportOptions{Collection: "items", Retries: 1, Backoff: time.Millisecond})
+
+	var want = ImportError{0, wedeploy.StatusError{Code: http.StatusServiceUnavailable}}
+
+	if err != want {
+		t.Errorf("Expected error %v, got %v instead", want, err)
+	}
+
+	_, err = Import(context.Background(), hc, strings.NewReader(`{"a":1}`+"\n{bad"),
+		ImportOptions{Collection: "items"})
+
+	if ie, ok := err.(ImportError); !ok || ie.Record != 1 {
+		t.Errorf("Expected decoding error on record 1, got %v instead", err)
+	}
+}
+
+func TestImportResume(t *testing.T) {
+	var dir, err = ioutil.TempDir("", "bulk")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	var e, s, _ = setupEmulator()
+	defer s.Close()
+
+	// fail the requests after the first 2 batches
+	var failing = &failAfter{n: 4}
+	var hc = wedeploy.NewHTTPClient(
+		wedeploy.WithBaseURL(s.URL),
+		wedeploy.WithHTTP(&http.Client{Transport: failing}))
+
+	var checkpoint = filepath.Join(dir, "checkpoint.json")
+	var o = ImportOptions{
+		Collection: "items",
+		BatchSize:  2,
+		Retries:    -1,
+		Checkpoint: checkpoint,
+	}
+
+	if _, err := Import(context.Background(), hc, strings.NewReader(ndjson(7)), o); err == nil {
+		t.Fatal("Expected import to fail")
+	}
+
+	var bin, _ = ioutil.ReadFile(checkpoint)
+
+	if string(bin) != `{"records":4}` {
+		t.Errorf("Expected checkpoint after 4 records, got %s instead", bin)
+	}
+
+	failing.set(-1)
+
+	n, err := Import(context.Background(), hc, strings.NewReader(ndjson(7)), o)
+
+	if err != nil || n != 3 {
+		t.Errorf("Expected remaining 3 documents to be imported, got %d (%v)", n, err)
+	}
+
+	if len(e.Documents("items")) != 7 {
+		t.Errorf("Expected 7 documents, got %d instead", len(e.Documents("items")))
+	}
+
+	if _, err := os.Stat(checkpoint); !os.IsNotExist(err) {
+		t.Errorf("Expected checkpoint to be removed")
+	}
+}
+
+// failAfter fails the requests after n requests, or never if n is negative
+type failAfter struct {
+	mutex sync.Mutex
+	n     int
+}
+
+func (f *failAfter) set(n int) {
+	f.mutex.Lock()
+	f.n = n
+	f.mutex.Unlock()
+}
+
+func (f *failAfter) RoundTrip(req *http.Request) (*http.Response, error) {
+	f.mutex.Lock()
+	var fail = f.n == 0
+
+	if f.n > 0 {
+		f.n--
+	}
+
+	f.mutex.Unlock()
+
+	if fail {
+		return nil, errors.New("connection refused")
+	}
+
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// loseResponses sends the first n requests, but fails with an error
+// instead of returning their responses
+type loseResponses struct {
+	mutex sync.Mutex
+	n     int
+}
+
+func (l *loseResponses) RoundTrip(req *http.Request) (*http.Response, error) {
+	var resp, err = http.DefaultTransport.RoundTrip(req)
+
+	l.mutex.Lock()
+	var lose = l.n > 0
+	l.n--
+	l.mutex.Unlock()
+
+	if err != nil || !lose {
+		return resp, err
+	}
+
+	resp.Body.Close()
+	return nil, errors.New("connection reset")
+}
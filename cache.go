@@ -0,0 +1,381 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wedeploy
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// CachedResponse is a response stored in a Cache
+type CachedResponse struct {
+	StatusCode int         `json:"status"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+}
+
+// Cache stores responses for revalidation with ETag and Last-Modified.
+// Implementations must be safe for concurrent use.
+type Cache interface {
+	Get(key string) (*CachedResponse, bool)
+	Set(key string, response *CachedResponse)
+	Delete(key string)
+}
+
+// DefaultVaryHeaders are the request headers that are part of the cache key
+var DefaultVaryHeaders = []string{"Accept", "Authorization"}
+
+// WithCache caches GET and HEAD responses that have an ETag or Last-Modified
+// header. Requests for a cached response are sent with If-None-Match and
+// If-Modified-Since, and a 304 Not Modified response is replaced by the
+// cached response. Responses are keyed by method, URL, request body,
+// and the vary headers (DefaultVaryHeaders, if none is given).
+func WithCache(c Cache, varyHeaders ...string) Option {
+	if len(varyHeaders) == 0 {
+		varyHeaders = DefaultVaryHeaders
+	}
+
+	return func(h *HTTPClient) {
+		h.cache = &responseCache{
+			store:       c,
+			varyHeaders: varyHeaders,
+		}
+	}
+}
+
+type responseCache struct {
+	store       Cache
+	varyHeaders []string
+}
+
+func (c *responseCache) wrap(w *WeDeploy, next roundTrip) roundTrip {
+	return func(req *http.Request) (*http.Response, error) {
+		if req.Method != "GET" && req.Method != "HEAD" {
+			return next(req)
+		}
+
+		var key, ok = c.key(w, req)
+
+		if !ok {
+			return next(req)
+		}
+
+		var cached, found = c.store.Get(key)
+
+		if found {
+			req = conditional(req, cached)
+		}
+
+		var resp, err = next(req)
+
+		if err != nil {
+			return resp, err
+		}
+
+		if found && resp.StatusCode == http.StatusNotModified {
+			return c.revalidated(key, cached, resp)
+		}
+
+		return c.maybeStore(key, resp, w.getMaxResponseSize())
+	}
+}
+
+// key for the request, if its body can be read without consuming it.
+// Requests with equivalent queries have the same key.
+func (c *responseCache) key(w *WeDeploy, req *http.Request) (string, bool) {
+	var hash = sha256.New()
+
+	io.WriteString(hash, req.Method+" "+req.URL.String()+"\n")
+
+	if !writeBodyKey(hash, w, req) {
+		return "", false
+	}
+
+	for _, name := range c.varyHeaders {
+		io.WriteString(hash, "\n"+http.CanonicalHeaderKey(name)+": "+
+			strings.Join(req.Header[http.CanonicalHeaderKey(name)], ", "))
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), true
+}
+
+// writeBodyKey writes the canonical query of the request, or else its
+// body, to the hash of a key, returning false if the body can't be read
+// without consuming it
+func writeBodyKey(hash io.Writer, w *WeDeploy, req *http.Request) bool {
+	if w.Query != nil {
+		if bin, err := w.Query.Canonical(); err == nil {
+			hash.Write(bin)
+			return true
+		}
+	}
+
+	if req.Body == nil || req.Body == http.NoBody {
+		return true
+	}
+
+	if req.GetBody == nil {
+		return false
+	}
+
+	var body, err = req.GetBody()
+
+	if err != nil {
+		return false
+	}
+
+	_, err = io.Copy(hash, body)
+	body.Close()
+	return err == nil
+}
+
+func conditional(req *http.Request, cached *CachedResponse) *http.Request {
+	var r = *req
+	r.Header = cloneHeader(req.Header)
+
+	if etag := cached.Header.Get("ETag"); etag != "" {
+		r.Header.Set("If-None-Match", etag)
+	}
+
+	if lm := cached.Header.Get("Last-Modified"); lm != "" {
+		r.Header.Set("If-Modified-Since", lm)
+	}
+
+	return &r
+}
+
+func (c *responseCache) revalidated(
+	key string, cached *CachedResponse, resp *http.Response) (*http.Response, error) {
+	io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+
+	var header = cloneHeader(cached.Header)
+
+	for _, k := range []string{"ETag", "Last-Modified", "Date", "Expires", "Cache-Control"} {
+		if v, ok := resp.Header[k]; ok {
+			header[k] = v
+		}
+	}
+
+	var updated = &CachedResponse{
+		StatusCode: cached.StatusCode,
+		Header:     header,
+		Body:       cached.Body,
+	}
+
+	c.store.Set(key, updated)
+
+	var r = *resp
+	r.Status = http.StatusText(updated.StatusCode)
+	r.StatusCode = updated.StatusCode
+	r.Header = cloneHeader(header)
+	r.Header.Set("X-From-Cache", "1")
+	r.ContentLength = int64(len(updated.Body))
+	r.Body = ioutil.NopCloser(bytes.NewReader(updated.Body))
+	return &r, nil
+}
+
+// maybeStore the response, if it is cacheable and its body isn't larger
+// than the maximum response size, which is read up to one byte more
+func (c *responseCache) maybeStore(key string, resp *http.Response, maxResponseSize int64) (*http.Response, error) {
+	if resp.StatusCode != http.StatusOK ||
+		(resp.Header.Get("ETag") == "" && resp.Header.Get("Last-Modified") == "") ||
+		strings.Contains(resp.Header.Get("Cache-Control"), "no-store") {
+		return resp, nil
+	}
+
+	var r io.Reader = resp.Body
+
+	if maxResponseSize > 0 {
+		r = io.LimitReader(r, maxResponseSize+1)
+	}
+
+	var body, err = ioutil.ReadAll(r)
+
+	if err == nil && maxResponseSize > 0 && int64(len(body)) > maxResponseSize {
+		// keep the body, so that reading it fails for being too large
+		resp.Body = &prefixedBody{
+			Reader: io.MultiReader(bytes.NewReader(body), resp.Body),
+			Closer: resp.Body,
+		}
+
+		return resp, nil
+	}
+
+	resp.Body.Close()
+
+	if err != nil {
+		return resp, err
+	}
+
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	c.store.Set(key, &CachedResponse{
+		StatusCode: resp.StatusCode,
+		Header:     cloneHeader(resp.Header),
+		Body:       body,
+	})
+
+	return resp, nil
+}
+
+// MemoryCache is an in-memory least recently used Cache
+type MemoryCache struct {
+	max     int
+	entries map[string]*list.Element
+	lru     *list.List
+	mutex   sync.Mutex
+}
+
+type memoryCacheEntry struct {
+	key      string
+	response *CachedResponse
+}
+
+// NewMemoryCache creates an in-memory cache holding up to max responses.
+// The least recently used responses are evicted first.
+func NewMemoryCache(max int) *MemoryCache {
+	return &MemoryCache{
+		max:     max,
+		entries: map[string]*list.Element{},
+		lru:     list.New(),
+	}
+}
+
+// Get a cached response
+func (m *MemoryCache) Get(key string) (*CachedResponse, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var e, ok = m.entries[key]
+
+	if !ok {
+		return nil, false
+	}
+
+	m.lru.MoveToFront(e)
+	return e.Value.(*memoryCacheEntry).response, true
+}
+
+// Set a cached response
+func (m *MemoryCache) Set(key string, response *CachedResponse) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if e, ok := m.entries[key]; ok {
+		e.Value.(*memoryCacheEntry).response = response
+		m.lru.MoveToFront(e)
+		return
+	}
+
+	m.entries[key] = m.lru.PushFront(&memoryCacheEntry{key, response})
+
+	for m.max > 0 && m.lru.Len() > m.max {
+		var oldest = m.lru.Back()
+		m.lru.Remove(oldest)
+		delete(m.entries, oldest.Value.(*memoryCacheEntry).key)
+	}
+}
+
+// Delete a cached response
+func (m *MemoryCache) Delete(key string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if e, ok := m.entries[key]; ok {
+		m.lru.Remove(e)
+		delete(m.entries, key)
+	}
+}
+
+// Len is the number of cached responses
+func (m *MemoryCache) Len() int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.lru.Len()
+}
+
+// DiskCache is a Cache storing responses as files on a directory
+type DiskCache struct {
+	dir string
+}
+
+// NewDiskCache creates a cache storing responses on the given directory
+func NewDiskCache(dir string) *DiskCache {
+	return &DiskCache{
+		dir: dir,
+	}
+}
+
+// Get a cached response
+func (d *DiskCache) Get(key string) (*CachedResponse, bool) {
+	var bin, err = ioutil.ReadFile(d.path(key))
+
+	if err != nil {
+		return nil, false
+	}
+
+	var response CachedResponse
+
+	if err := json.Unmarshal(bin, &response); err != nil {
+		return nil, false
+	}
+
+	return &response, true
+}
+
+// Set a cached response
+// Failures are ignored, as the response can be fetched again
+func (d *DiskCache) Set(key string, response *CachedResponse) {
+	var bin, err = json.Marshal(response)
+
+	if err != nil {
+		return
+	}
+
+	if err = os.MkdirAll(d.dir, 0700); err != nil {
+		return
+	}
+
+	tmp, err := ioutil.TempFile(d.dir, "tmp-")
+
+	if err != nil {
+		return
+	}
+
+	_, err = tmp.Write(bin)
+
+	if ec := tmp.Close(); err == nil {
+		err = ec
+	}
+
+	if err == nil {
+		err = os.Rename(tmp.Name(), d.path(key))
+	}
+
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+// Delete a cached response
+func (d *DiskCache) Delete(key string) {
+	os.Remove(d.path(key))
+}
+
+func (d *DiskCache) path(key string) string {
+	var hash = sha256.Sum256([]byte(key))
+	return filepath.Join(d.dir, hex.EncodeToString(hash[:]))
+}
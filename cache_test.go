@@ -0,0 +1,328 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wedeploy
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/henvic/wedeploy-sdk-go/filter"
+)
+
+func TestCacheETagRevalidation(t *testing.T) {
+	var requests int32
+
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("ETag", `"v1"`)
+
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		fmt.Fprintf(w, `{"title":"cached"}`)
+	}))
+	defer s.Close()
+
+	var hc = NewHTTPClient(WithCache(NewMemoryCache(10)))
+
+	for i := 0; i < 3; i++ {
+		var req = hc.URL(s.URL, "books")
+
+		if err := req.Get(); err != nil {
+			t.Fatal(err)
+		}
+
+		var fromCache = req.Response.Header.Get("X-From-Cache") == "1"
+
+		if fromCache != (i != 0) {
+			t.Errorf("Expected response %d from cache to be %v", i, i != 0)
+		}
+
+		assertStatusCode(t, http.StatusOK, req.Response.StatusCode)
+
+		var book struct {
+			Title string `json:"title"`
+		}
+
+		if err := req.DecodeJSON(&book); err != nil {
+			t.Error(err)
+		}
+
+		if book.Title != "cached" {
+			t.Errorf("Expected cached title, got %v instead", book.Title)
+		}
+
+		if req.Request.Header.Get("If-None-Match") != "" {
+			t.Errorf("Expected conditional header not to leak to request headers")
+		}
+	}
+
+	if requests != 3 {
+		t.Errorf("Expected 3 requests to be revalidated, got %d instead", requests)
+	}
+}
+
+func TestCacheMaxResponseSize(t *testing.T) {
+	var body = `["` + strings.Repeat("a", 100) + `"]`
+
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		fmt.Fprint(w, body)
+	}))
+	defer s.Close()
+
+	var cache = NewMemoryCache(10)
+	var hc = NewHTTPClient(WithCache(cache), WithMaxResponseSize(50))
+	var req = hc.URL(s.URL, "books")
+
+	if err := req.Get(); err != nil {
+		t.Fatal(err)
+	}
+
+	var v []string
+
+	if err := req.DecodeJSON(&v); err != (ErrResponseTooLarge{50}) {
+		t.Errorf("Expected error %v, got %v instead", ErrResponseTooLarge{50}, err)
+	}
+
+	if len(cache.entries) != 0 {
+		t.Errorf("Expected response larger than the maximum size not to be cached")
+	}
+
+	req = hc.URL(s.URL, "books").MaxResponseSize(int64(len(body)))
+
+	if err := req.Get(); err != nil {
+		t.Fatal(err)
+	}
+
+	assertTextualBody(t, body, req.Response.Body)
+
+	if len(cache.entries) != 1 {
+		t.Errorf("Expected response to be cached")
+	}
+}
+
+func TestCacheLastModifiedRevalidation(t *testing.T) {
+	var lastModified = "Wed, 21 Oct 2015 07:28:00 GMT"
+
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Last-Modified", lastModified)
+
+		if r.Header.Get("If-Modified-Since") == lastModified {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		fmt.Fprintf(w, `"body"`)
+	}))
+	defer s.Close()
+
+	var hc = NewHTTPClient(WithCache(NewMemoryCache(10)))
+
+	for i := 0; i < 2; i++ {
+		var req = hc.URL(s.URL)
+
+		if err := req.Get(); err != nil {
+			t.Fatal(err)
+		}
+
+		assertTextualBody(t, `"body"`, req.Response.Body)
+	}
+}
+
+func TestCacheKeyedByQuery(t *testing.T) {
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body, _ = ioutil.ReadAll(r.Body)
+		w.Header().Set("ETag", fmt.Sprintf(`"%x"`, body))
+
+		if r.Header.Get("If-None-Match") != "" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Write(body)
+	}))
+	defer s.Close()
+
+	var cache = NewMemoryCache(10)
+	var hc = NewHTTPClient(WithCache(cache))
+
+	for _, limit := range []int{1, 2, 1} {
+		var req = hc.URL(s.URL).Limit(limit)
+
+		if err := req.Get(); err != nil {
+			t.Fatal(err)
+		}
+
+		assertTextualBody(t, fmt.Sprintf(`{"limit":%d}`, limit), req.Response.Body)
+	}
+
+	if cache.Len() != 2 {
+		t.Errorf("Expected 2 cached responses, got %d instead", cache.Len())
+	}
+}
+
+func TestCacheKeyedByCanonicalQuery(t *testing.T) {
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`[]`))
+	}))
+	defer s.Close()
+
+	var cache = NewMemoryCache(10)
+	var hc = NewHTTPClient(WithCache(cache))
+
+	for _, req := range []*WeDeploy{
+		hc.URL(s.URL).Filter("a", 1).Filter("b", 2),
+		hc.URL(s.URL).Filter("b", 2).Filter("a", 1),
+		hc.URL(s.URL).Filter(filter.And(filter.Equal("a", 1), filter.Equal("b", 2))),
+	} {
+		if err := req.Get(); err != nil {
+			t.Fatal(err)
+		}
+
+		req.Response.Body.Close()
+	}
+
+	if cache.Len() != 1 {
+		t.Errorf("Expected equivalent queries to share the cached response, got %d instead", cache.Len())
+	}
+}
+
+func TestCacheSkipsUncacheable(t *testing.T) {
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+
+		if r.URL.Path == "/no-store" {
+			w.Header().Set("Cache-Control", "no-store")
+		}
+
+		if r.URL.Path == "/error" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+
+		if r.Header.Get("If-None-Match") != "" {
+			t.Errorf("Unexpected conditional request")
+		}
+	}))
+	defer s.Close()
+
+	var cache = NewMemoryCache(10)
+	var hc = NewHTTPClient(WithCache(cache))
+
+	for i := 0; i < 2; i++ {
+		if err := hc.URL(s.URL, "post").Post(); err != nil {
+			t.Error(err)
+		}
+
+		if err := hc.URL(s.URL, "no-store").Get(); err != nil {
+			t.Error(err)
+		}
+
+		if err := hc.URL(s.URL, "error").Get(); err == nil {
+			t.Errorf("Expected error")
+		}
+	}
+
+	if cache.Len() != 0 {
+		t.Errorf("Expected no cached responses, got %d instead", cache.Len())
+	}
+}
+
+func TestCacheVaryHeaders(t *testing.T) {
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		fmt.Fprintf(w, "%s", r.Header.Get("Authorization"))
+	}))
+	defer s.Close()
+
+	var cache = NewMemoryCache(10)
+	var hc = NewHTTPClient(WithCache(cache))
+
+	for _, token := range []string{"a", "b", "a"} {
+		if err := hc.URL(s.URL).Auth(token).Get(); err != nil {
+			t.Error(err)
+		}
+	}
+
+	if cache.Len() != 2 {
+		t.Errorf("Expected 2 cached responses, got %d instead", cache.Len())
+	}
+}
+
+func TestMemoryCacheLRU(t *testing.T) {
+	var cache = NewMemoryCache(2)
+	var a, b, c = &CachedResponse{StatusCode: 1}, &CachedResponse{StatusCode: 2},
+		&CachedResponse{StatusCode: 3}
+
+	cache.Set("a", a)
+	cache.Set("b", b)
+	cache.Get("a")
+	cache.Set("c", c)
+
+	if _, ok := cache.Get("b"); ok {
+		t.Errorf("Expected least recently used response to be evicted")
+	}
+
+	if got, ok := cache.Get("a"); !ok || got != a {
+		t.Errorf("Expected response a to be cached")
+	}
+
+	cache.Set("c", a)
+
+	if got, _ := cache.Get("c"); got != a {
+		t.Errorf("Expected response c to be replaced")
+	}
+
+	cache.Delete("c")
+
+	if cache.Len() != 1 {
+		t.Errorf("Expected 1 cached response, got %d instead", cache.Len())
+	}
+}
+
+func TestDiskCache(t *testing.T) {
+	var dir, err = ioutil.TempDir("", "wedeploy-cache")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	var cache = NewDiskCache(dir + "/responses")
+	var want = &CachedResponse{
+		StatusCode: 200,
+		Header:     http.Header{"Etag": []string{`"v1"`}},
+		Body:       []byte("body"),
+	}
+
+	if _, ok := cache.Get("key"); ok {
+		t.Errorf("Expected no cached response")
+	}
+
+	cache.Set("key", want)
+
+	if got, ok := cache.Get("key"); !ok || !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected cached response %+v, got %+v instead", want, got)
+	}
+
+	cache.Delete("key")
+
+	if _, ok := cache.Get("key"); ok {
+		t.Errorf("Expected cached response to be deleted")
+	}
+}
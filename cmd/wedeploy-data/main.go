@@ -0,0 +1,302 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Command wedeploy-data exports Data collections to NDJSON or CSV files
+// and imports them back.
+//
+//	wedeploy-data export -collection books -filter '{"year":{"operator":">","value":1600}}' -sort id -o books.csv
+//	wedeploy-data import -collection books -checkpoint books.checkpoint books.csv
+//
+// The project URL and credentials are read with wedeploy.LoadConfig,
+// from the WEDEPLOY_* environment variables or a profile file.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/henvic/wedeploy-sdk-go"
+	"github.com/henvic/wedeploy-sdk-go/bulk"
+	"github.com/henvic/wedeploy-sdk-go/filter"
+	"github.com/henvic/wedeploy-sdk-go/query"
+)
+
+const usage = `Usage: wedeploy-data <export|import> [flags]
+
+Run wedeploy-data <command> -h for the flags of a command.
+`
+
+func main() {
+	var ctx, cancel = context.WithCancel(context.Background())
+	var sigs = make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt)
+
+	go func() {
+		<-sigs
+		cancel()
+	}()
+
+	os.Exit(run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	var err error
+
+	switch args[0] {
+	case "export":
+		err = export(ctx, args[1:], stdout, stderr)
+	case "import":
+		err = importFile(ctx, args[1:], stdin, stdout, stderr)
+	default:
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	if err == flag.ErrHelp {
+		return 2
+	}
+
+	if err != nil {
+		fmt.Fprintf(stderr, "wedeploy-data: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
+
+type clientFlags struct {
+	config  string
+	profile string
+	url     string
+}
+
+func (c *clientFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&c.config, "config", "", "profile file (default $WEDEPLOY_CONFIG)")
+	fs.StringVar(&c.profile, "profile", "", "profile (default $WEDEPLOY_PROFILE)")
+	fs.StringVar(&c.url, "url", "", "Data service URL, overriding the configuration")
+}
+
+func (c *clientFlags) client() (*wedeploy.HTTPClient, error) {
+	var conf, err = wedeploy.LoadConfig(c.config, c.profile)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if c.url != "" {
+		conf.URL = c.url
+	}
+
+	if conf.BaseURL() == "" {
+		return nil, errors.New("missing Data service URL: use -url or WEDEPLOY_URL")
+	}
+
+	return conf.HTTPClient(), nil
+}
+
+func export(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	var fs = flag.NewFlagSet("export", flag.ContinueOnError)
+	var cf clientFlags
+	var collection = fs.String("collection", "", "collection to export (required)")
+	var filters = fs.String("filter", "", "filter as JSON, such as {\"year\":{\"operator\":\">\",\"value\":1600}}")
+	var sort = fs.String("sort", "", "comma-separated fields to sort by, with an optional :desc suffix")
+	var format = fs.String("format", "", "ndjson or csv (default from the output file extension)")
+	var fields = fs.String("fields", "", "comma-separated CSV columns (default the fields of the first document)")
+	var pageSize = fs.Int("page-size", bulk.DefaultPageSize, "documents fetched per request")
+	var output = fs.String("o", "", "output file (default stdout)")
+
+	cf.register(fs)
+	fs.SetOutput(stderr)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *collection == "" {
+		return errors.New("missing -collection")
+	}
+
+	var q, err = buildQuery(*filters, *sort)
+
+	if err != nil {
+		return err
+	}
+
+	hc, err := cf.client()
+
+	if err != nil {
+		return err
+	}
+
+	var o = bulk.ExportOptions{
+		Collection: *collection,
+		Query:      q,
+		Format:     bulk.Format(*format),
+		Fields:     splitList(*fields),
+		PageSize:   *pageSize,
+	}
+
+	var w = stdout
+	var f *os.File
+
+	if *output != "" {
+		if f, err = os.Create(*output); err != nil {
+			return err
+		}
+
+		w = f
+
+		if o.Format == "" {
+			o.Format = bulk.FormatFromPath(*output)
+		}
+	}
+
+	n, err := bulk.Export(ctx, hc, w, o)
+
+	// a failure to close the file can lose the end of the export
+	if f != nil {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stderr, "%d documents exported\n", n)
+	return nil
+}
+
+func importFile(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	var fs = flag.NewFlagSet("import", flag.ContinueOnError)
+	var cf clientFlags
+	var collection = fs.String("collection", "", "collection to import to (required)")
+	var format = fs.String("format", "", "ndjson or csv (default from the input file extension)")
+	var batchSize = fs.Int("batch-size", 100, "documents sent before saving the checkpoint")
+	var concurrency = fs.Int("concurrency", 4, "documents sent in parallel")
+	var retries = fs.Int("retries", 3, "retries of a failing document")
+	var backoff = fs.Duration("backoff", 500*time.Millisecond, "backoff before the first retry")
+	var checkpoint = fs.String("checkpoint", "", "file to resume an interrupted import from")
+
+	cf.register(fs)
+	fs.SetOutput(stderr)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *collection == "" {
+		return errors.New("missing -collection")
+	}
+
+	if fs.NArg() > 1 {
+		return errors.New("expected a single input file")
+	}
+
+	var hc, err = cf.client()
+
+	if err != nil {
+		return err
+	}
+
+	if *retries == 0 {
+		*retries = -1
+	}
+
+	var o = bulk.ImportOptions{
+		Collection:  *collection,
+		Format:      bulk.Format(*format),
+		BatchSize:   *batchSize,
+		Concurrency: *concurrency,
+		Retries:     *retries,
+		Backoff:     *backoff,
+		Checkpoint:  *checkpoint,
+	}
+
+	var r = stdin
+
+	if path := fs.Arg(0); path != "" && path != "-" {
+		var f, err = os.Open(path)
+
+		if err != nil {
+			return err
+		}
+
+		defer f.Close()
+		r = f
+
+		if o.Format == "" {
+			o.Format = bulk.FormatFromPath(path)
+		}
+	}
+
+	n, err := bulk.Import(ctx, hc, r, o)
+	fmt.Fprintf(stderr, "%d documents imported\n", n)
+	return err
+}
+
+// buildQuery from a JSON filter, or list of filters, and a sort list
+func buildQuery(filters, sort string) (*query.Builder, error) {
+	var q = query.New()
+
+	if filters != "" {
+		var list []filter.Filter
+
+		if strings.HasPrefix(strings.TrimSpace(filters), "[") {
+			if err := json.Unmarshal([]byte(filters), &list); err != nil {
+				return nil, fmt.Errorf("invalid -filter: %v", err)
+			}
+		} else {
+			var f filter.Filter
+
+			if err := json.Unmarshal([]byte(filters), &f); err != nil {
+				return nil, fmt.Errorf("invalid -filter: %v", err)
+			}
+
+			list = append(list, f)
+		}
+
+		for i := range list {
+			q.Filter(&list[i])
+		}
+	}
+
+	for _, s := range splitList(sort) {
+		var parts = strings.SplitN(s, ":", 2)
+
+		if len(parts) == 2 {
+			q.Sort(parts[0], parts[1])
+		} else {
+			q.Sort(parts[0])
+		}
+	}
+
+	return q, nil
+}
+
+func splitList(s string) []string {
+	var list []string
+
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+
+	return list
+}
@@ -0,0 +1,132 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/henvic/wedeploy-sdk-go/emulator"
+)
+
+func TestExportImport(t *testing.T) {
+	var dir, err = ioutil.TempDir("", "wedeploy-data")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	var source, target = emulator.New(), emulator.New()
+	var ss, ts = httptest.NewServer(source), httptest.NewServer(target)
+	defer ss.Close()
+	defer ts.Close()
+
+	var stdin = strings.NewReader(`{"id":"1","title":"Hamlet","year":1603}
+{"id":"2","title":"Macbeth","year":1623}
+{"id":"3","title":"Sonnets","year":1609}
+`)
+
+	var stderr bytes.Buffer
+
+	if code := run(context.Background(), []string{
+		"import", "-url", ss.URL, "-collection", "books", "-",
+	}, stdin, &bytes.Buffer{}, &stderr); code != 0 {
+		t.Fatalf("Expected import to succeed, got %d: %v", code, stderr.String())
+	}
+
+	var file = filepath.Join(dir, "books.csv")
+
+	if code := run(context.Background(), []string{
+		"export", "-url", ss.URL, "-collection", "books",
+		"-filter", `{"year":{"operator":">","value":1603}}`,
+		"-sort", "year:desc", "-o", file,
+	}, nil, &bytes.Buffer{}, &stderr); code != 0 {
+		t.Fatalf("Expected export to succeed, got %d: %v", code, stderr.String())
+	}
+
+	var bin, _ = ioutil.ReadFile(file)
+	var want = "id,title,year\n" +
+		"\"\"\"2\"\"\",Macbeth,1623\n" +
+		"\"\"\"3\"\"\",Sonnets,1609\n"
+
+	if string(bin) != want {
+		t.Errorf("Expected CSV %q, got %q instead", want, bin)
+	}
+
+	if code := run(context.Background(), []string{
+		"import", "-url", ts.URL, "-collection", "books", "-concurrency", "1", file,
+	}, nil, &bytes.Buffer{}, &stderr); code != 0 {
+		t.Fatalf("Expected import to succeed, got %d: %v", code, stderr.String())
+	}
+
+	var docs = target.Documents("books")
+	var wantDocs = []emulator.Document{
+		{"id": "2", "title": "Macbeth", "year": 1623.0},
+		{"id": "3", "title": "Sonnets", "year": 1609.0},
+	}
+
+	if !reflect.DeepEqual(docs, wantDocs) {
+		t.Errorf("Expected documents %v, got %v instead", wantDocs, docs)
+	}
+
+	if !strings.Contains(stderr.String(), "2 documents exported") {
+		t.Errorf("Expected export summary, got %v instead", stderr.String())
+	}
+}
+
+func TestExportStdout(t *testing.T) {
+	var e = emulator.New()
+	var s = httptest.NewServer(e)
+	defer s.Close()
+
+	var stdout bytes.Buffer
+
+	run(context.Background(), []string{"import", "-url", s.URL, "-collection", "books"},
+		strings.NewReader(`{"id":"1"}`), &bytes.Buffer{}, &bytes.Buffer{})
+
+	if code := run(context.Background(), []string{
+		"export", "-url", s.URL, "-collection", "books", "-filter", `[{"id":{"operator":"=","value":"1"}}]`,
+	}, nil, &stdout, &bytes.Buffer{}); code != 0 {
+		t.Fatalf("Expected export to succeed, got %d", code)
+	}
+
+	if stdout.String() != `{"id":"1"}`+"\n" {
+		t.Errorf("Unexpected export %v", stdout.String())
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	var cases = []struct {
+		args []string
+		code int
+		err  string
+	}{
+		{nil, 2, "Usage"},
+		{[]string{"unknown"}, 2, "Usage"},
+		{[]string{"export", "-h"}, 2, "-collection"},
+		{[]string{"export", "-url", "http://localhost"}, 1, "missing -collection"},
+		{[]string{"import", "-url", "http://localhost", "-collection", "a", "b", "c"}, 1, "single input file"},
+		{[]string{"export", "-url", "http://localhost", "-collection", "a", "-filter", "{"}, 1, "invalid -filter"},
+	}
+
+	for _, c := range cases {
+		var stderr bytes.Buffer
+
+		if code := run(context.Background(), c.args, nil, &bytes.Buffer{}, &stderr); code != c.code ||
+			!strings.Contains(stderr.String(), c.err) {
+			t.Errorf("Expected %v to exit with %d and %q, got %d and %q instead",
+				c.args, c.code, c.err, code, stderr.String())
+		}
+	}
+}
@@ -0,0 +1,383 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Command wedeploy queries WeDeploy Data from the command line.
+//
+//	wedeploy --filter 'year>1600' --sort year:desc --limit 5 --output table books
+//	wedeploy --search hamlet --highlight title --dry-run books
+//	wedeploy --agg 'avg_year:year:avg' --count books
+//
+// Filters and searches are conditions such as year>1600, title=Hamlet,
+// genre!=comedy, year<=1610, or title~^Ham, or JSON filters.
+// Values are read as JSON when valid, such as 1600 or true,
+// and as strings otherwise. A search without an operator matches the text.
+//
+// The URL is resolved against the URL of the profile read with
+// wedeploy.LoadConfig, from the WEDEPLOY_* environment variables
+// or a profile file.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/henvic/wedeploy-sdk-go"
+	"github.com/henvic/wedeploy-sdk-go/aggregation"
+	"github.com/henvic/wedeploy-sdk-go/filter"
+	"github.com/henvic/wedeploy-sdk-go/query"
+)
+
+func main() {
+	os.Exit(run(context.Background(), os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// listFlag is a flag that can be repeated
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ", ")
+}
+
+func (l *listFlag) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+type options struct {
+	config     string
+	profile    string
+	url        string
+	auth       string
+	method     string
+	filters    listFlag
+	searches   listFlag
+	sorts      listFlag
+	highlights listFlag
+	aggs       listFlag
+	limit      int
+	offset     int
+	count      bool
+	output     string
+	dryRun     bool
+}
+
+func parseFlags(args []string, stderr io.Writer) (*options, error) {
+	var o = &options{}
+	var fs = flag.NewFlagSet("wedeploy", flag.ContinueOnError)
+
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: wedeploy [flags] <url>")
+		fs.PrintDefaults()
+	}
+
+	fs.StringVar(&o.config, "config", "", "profile file (default $WEDEPLOY_CONFIG)")
+	fs.StringVar(&o.profile, "profile", "", "profile (default $WEDEPLOY_PROFILE)")
+	fs.StringVar(&o.auth, "auth", "", "token, or username:password")
+	fs.StringVar(&o.method, "method", "GET", "HTTP method")
+	fs.Var(&o.filters, "filter", "filter condition, such as year>1600 (repeatable)")
+	fs.Var(&o.searches, "search", "search text or condition (repeatable)")
+	fs.Var(&o.sorts, "sort", "field to sort by, with an optional :desc suffix (repeatable)")
+	fs.Var(&o.highlights, "highlight", "field to highlight (repeatable)")
+	fs.Var(&o.aggs, "agg", "aggregation as name:field:operator[:value] (repeatable)")
+	fs.IntVar(&o.limit, "limit", -1, "maximum number of documents")
+	fs.IntVar(&o.offset, "offset", -1, "number of documents to skip")
+	fs.BoolVar(&o.count, "count", false, "count the documents instead of fetching them")
+	fs.StringVar(&o.output, "output", "json", "output format: json or table")
+	fs.BoolVar(&o.dryRun, "dry-run", false, "print the request without sending it")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return nil, flag.ErrHelp
+	}
+
+	o.method = strings.ToUpper(o.method)
+
+	if _, ok := methods[o.method]; !ok {
+		return nil, fmt.Errorf("unsupported method %q", o.method)
+	}
+
+	if o.output != "json" && o.output != "table" {
+		return nil, fmt.Errorf("invalid output format %q", o.output)
+	}
+
+	o.url = fs.Arg(0)
+	return o, nil
+}
+
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	var o, err = parseFlags(args, stderr)
+
+	if err == flag.ErrHelp {
+		return 2
+	}
+
+	if err == nil {
+		err = send(ctx, o, stdout)
+	}
+
+	if err != nil {
+		fmt.Fprintf(stderr, "wedeploy: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
+
+func send(ctx context.Context, o *options, stdout io.Writer) error {
+	var conf, err = wedeploy.LoadConfig(o.config, o.profile)
+
+	if err != nil {
+		return err
+	}
+
+	var req = conf.HTTPClient().URL(o.url)
+	req.SetContext(ctx)
+
+	if o.auth != "" {
+		req.Auth(strings.SplitN(o.auth, ":", 2)...)
+	}
+
+	if req.Query, err = buildQuery(o); err != nil {
+		return err
+	}
+
+	if o.dryRun {
+		return dryRun(req, o.method, stdout)
+	}
+
+	err = do(req, o.method)
+
+	if req.Response == nil {
+		return err
+	}
+
+	defer req.Response.Body.Close()
+
+	var body, errr = ioutil.ReadAll(req.Response.Body)
+
+	if err != nil {
+		// show the error body returned by the server, if any
+		if len(bytes.TrimSpace(body)) != 0 {
+			return fmt.Errorf("%v\n%s", err, bytes.TrimSpace(body))
+		}
+
+		return err
+	}
+
+	if errr != nil {
+		return errr
+	}
+
+	return printBody(stdout, body, o.output)
+}
+
+var methods = map[string]func(*wedeploy.WeDeploy) error{
+	"GET":    (*wedeploy.WeDeploy).Get,
+	"HEAD":   (*wedeploy.WeDeploy).Head,
+	"POST":   (*wedeploy.WeDeploy).Post,
+	"PUT":    (*wedeploy.WeDeploy).Put,
+	"PATCH":  (*wedeploy.WeDeploy).Patch,
+	"DELETE": (*wedeploy.WeDeploy).Delete,
+}
+
+func do(req *wedeploy.WeDeploy, method string) error {
+	return methods[method](req)
+}
+
+// buildQuery maps the flags to the query builder methods
+func buildQuery(o *options) (*query.Builder, error) {
+	var q = query.New()
+	var empty = true
+
+	for _, s := range o.filters {
+		var f, err = parseCondition(s)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if f == nil {
+			return nil, fmt.Errorf("invalid filter %q: missing operator", s)
+		}
+
+		q.Filter(f)
+		empty = false
+	}
+
+	for _, s := range o.searches {
+		var f, err = parseCondition(s)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if f == nil {
+			q.Search(s)
+		} else {
+			q.Search(f)
+		}
+
+		empty = false
+	}
+
+	for _, s := range o.sorts {
+		var parts = strings.SplitN(s, ":", 2)
+		q.Sort(parts[0], parts[1:]...)
+		empty = false
+	}
+
+	for _, h := range o.highlights {
+		q.Highlight(h)
+		empty = false
+	}
+
+	for _, s := range o.aggs {
+		var a, err = parseAggregation(s)
+
+		if err != nil {
+			return nil, err
+		}
+
+		q.Aggregate(a)
+		empty = false
+	}
+
+	if o.limit >= 0 {
+		q.Limit(o.limit)
+		empty = false
+	}
+
+	if o.offset >= 0 {
+		q.Offset(o.offset)
+		empty = false
+	}
+
+	if o.count {
+		q.Count()
+		empty = false
+	}
+
+	if empty {
+		return nil, nil
+	}
+
+	return q, nil
+}
+
+// operators recognized on conditions, longest first
+var operators = []string{">=", "<=", "!=", "=", ">", "<", "~"}
+
+// parseCondition parses a JSON filter or a condition such as year>1600,
+// returning nil if there is no operator
+func parseCondition(s string) (*filter.Filter, error) {
+	if strings.HasPrefix(strings.TrimSpace(s), "{") {
+		var f filter.Filter
+
+		if err := json.Unmarshal([]byte(s), &f); err != nil {
+			return nil, fmt.Errorf("invalid filter %q: %v", s, err)
+		}
+
+		return &f, nil
+	}
+
+	var at, operator = -1, ""
+
+	for _, op := range operators {
+		if i := strings.Index(s, op); i > 0 && (at == -1 || i < at) {
+			at, operator = i, op
+		}
+	}
+
+	if at == -1 {
+		return nil, nil
+	}
+
+	var field = strings.TrimSpace(s[:at])
+	var value = jsonValue(strings.TrimSpace(s[at+len(operator):]))
+
+	switch operator {
+	case "=":
+		return filter.Equal(field, value), nil
+	case "<=":
+		return filter.Lte(field, value), nil
+	}
+
+	return filter.New(field, operator, value), nil
+}
+
+// parseAggregation parses name:field:operator[:value]
+func parseAggregation(s string) (*aggregation.Aggregation, error) {
+	var parts = strings.SplitN(s, ":", 4)
+
+	if len(parts) < 3 {
+		return nil, fmt.Errorf("invalid aggregation %q: expected name:field:operator[:value]", s)
+	}
+
+	var value interface{}
+
+	if len(parts) == 4 {
+		value = jsonValue(parts[3])
+	}
+
+	return aggregation.New(parts[0], parts[1], parts[2], value), nil
+}
+
+// jsonValue decodes a JSON value, or else keeps it as a string
+func jsonValue(s string) interface{} {
+	var v interface{}
+
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		return s
+	}
+
+	return v
+}
+
+func dryRun(req *wedeploy.WeDeploy, method string, stdout io.Writer) error {
+	fmt.Fprintf(stdout, "%s %s\n", method, req.URL)
+
+	if req.Query == nil {
+		return nil
+	}
+
+	fmt.Fprintln(stdout)
+
+	var e = json.NewEncoder(stdout)
+	e.SetEscapeHTML(false)
+	e.SetIndent("", "    ")
+	return e.Encode(req.Query)
+}
+
+func printBody(stdout io.Writer, body []byte, output string) error {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+
+	if output == "table" {
+		return printTable(stdout, body)
+	}
+
+	var b bytes.Buffer
+
+	if err := json.Indent(&b, body, "", "    "); err != nil {
+		return errors.New("response is not JSON")
+	}
+
+	b.WriteString("\n")
+	_, err := b.WriteTo(stdout)
+	return err
+}
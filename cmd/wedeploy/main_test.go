@@ -0,0 +1,317 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/henvic/wedeploy-sdk-go/emulator"
+	"github.com/henvic/wedeploy-sdk-go/filter"
+)
+
+func setupEmulator(t *testing.T) (*emulator.Emulator, *httptest.Server) {
+	var e = emulator.New()
+	var s = httptest.NewServer(e)
+
+	for _, doc := range []string{
+		`{"id":"1","title":"Hamlet","year":1603}`,
+		`{"id":"2","title":"Macbeth","year":1623}`,
+		`{"id":"3","title":"Sonnets","year":1609}`,
+	} {
+		var resp, err = http.Post(s.URL+"/books", "application/json", strings.NewReader(doc))
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp.Body.Close()
+	}
+
+	return e, s
+}
+
+func TestRunTable(t *testing.T) {
+	var _, s = setupEmulator(t)
+	defer s.Close()
+
+	var stdout, stderr bytes.Buffer
+
+	if code := run(context.Background(), []string{
+		"--filter", "year>1603", "--sort", "year:desc", "--limit", "1",
+		"--output", "table", s.URL + "/books",
+	}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected run to succeed, got %d: %v", code, stderr.String())
+	}
+
+	var want = "ID  TITLE    YEAR\n" +
+		"2   Macbeth  1623\n"
+
+	if stdout.String() != want {
+		t.Errorf("Expected table %q, got %q instead", want, stdout.String())
+	}
+}
+
+func TestRunJSON(t *testing.T) {
+	var _, s = setupEmulator(t)
+	defer s.Close()
+
+	var stdout, stderr bytes.Buffer
+
+	if code := run(context.Background(), []string{
+		"--filter", "title~^S", s.URL + "/books",
+	}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected run to succeed, got %d: %v", code, stderr.String())
+	}
+
+	var want = `[
+    {
+        "id": "3",
+        "title": "Sonnets",
+        "year": 1609
+    }
+]
+`
+
+	if stdout.String() != want {
+		t.Errorf("Expected JSON %q, got %q instead", want, stdout.String())
+	}
+}
+
+func TestRunCount(t *testing.T) {
+	var _, s = setupEmulator(t)
+	defer s.Close()
+
+	var stdout, stderr bytes.Buffer
+
+	if code := run(context.Background(), []string{
+		"--count", "--filter", "year<=1609", "--output", "table", s.URL + "/books",
+	}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected run to succeed, got %d: %v", code, stderr.String())
+	}
+
+	if stdout.String() != "2\n" {
+		t.Errorf("Expected count 2, got %q instead", stdout.String())
+	}
+}
+
+func TestRunProfile(t *testing.T) {
+	var e, _ = setupEmulator(t)
+	var auth string
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		e.ServeHTTP(w, r)
+	}))
+	defer s.Close()
+
+	var dir, err = ioutil.TempDir("", "wedeploy")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	var config = filepath.Join(dir, "config.json")
+
+	if err = ioutil.WriteFile(config, []byte(`{
+	"default": "production",
+	"profiles": {
+		"production": {"url": "http://localhost:1"},
+		"local": {"url": "`+s.URL+`", "token": "secret"}
+	}
+}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	if code := run(context.Background(), []string{
+		"--config", config, "--profile", "local", "--count", "books",
+	}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected run to succeed, got %d: %v", code, stderr.String())
+	}
+
+	if stdout.String() != "3\n" {
+		t.Errorf("Expected count 3, got %q instead", stdout.String())
+	}
+
+	if auth != "Bearer secret" {
+		t.Errorf("Expected token from the profile, got %q instead", auth)
+	}
+}
+
+func TestRunAuth(t *testing.T) {
+	var auth string
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		w.Write([]byte("[]"))
+	}))
+	defer s.Close()
+
+	var stdout, stderr bytes.Buffer
+
+	if code := run(context.Background(), []string{
+		"--auth", "admin:safe", s.URL + "/books",
+	}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected run to succeed, got %d: %v", code, stderr.String())
+	}
+
+	if auth != "Basic YWRtaW46c2FmZQ==" {
+		t.Errorf("Expected basic auth, got %q instead", auth)
+	}
+}
+
+func TestRunDryRun(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	if code := run(context.Background(), []string{
+		"--filter", "year>1600", "--search", "hamlet", "--highlight", "title",
+		"--agg", "years:year:histogram:10", "--sort", "year:desc",
+		"--offset", "10", "--limit", "5", "--dry-run", "http://localhost:1/books",
+	}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected run to succeed, got %d: %v", code, stderr.String())
+	}
+
+	var want = `GET http://localhost:1/books
+
+{
+    "aggregation": [
+        {
+            "year": {
+                "name": "years",
+                "operator": "histogram",
+                "value": 10
+            }
+        }
+    ],
+    "filter": [
+        {
+            "year": {
+                "operator": ">",
+                "value": 1600
+            }
+        }
+    ],
+    "highlight": [
+        "title"
+    ],
+    "offset": 10,
+    "limit": 5,
+    "search": [
+        {
+            "*": {
+                "operator": "match",
+                "value": "hamlet"
+            }
+        }
+    ],
+    "sort": [
+        {
+            "year": "desc"
+        }
+    ]
+}
+`
+
+	if stdout.String() != want {
+		t.Errorf("Expected dry run %v, got %v instead", want, stdout.String())
+	}
+}
+
+func TestRunError(t *testing.T) {
+	var _, s = setupEmulator(t)
+	defer s.Close()
+
+	var stdout, stderr bytes.Buffer
+
+	if code := run(context.Background(), []string{
+		"--filter", `{"year":{"operator":"near","value":1}}`, s.URL + "/books",
+	}, &stdout, &stderr); code != 1 {
+		t.Errorf("Expected run to fail, got %d instead", code)
+	}
+
+	if !strings.Contains(stderr.String(), "unsupported operator") {
+		t.Errorf("Expected error body on stderr, got %q instead", stderr.String())
+	}
+}
+
+func TestRunUsage(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	if code := run(context.Background(), nil, &stdout, &stderr); code != 2 {
+		t.Errorf("Expected usage exit code 2, got %d instead", code)
+	}
+
+	if code := run(context.Background(), []string{
+		"--output", "yaml", "books",
+	}, &stdout, &stderr); code != 1 {
+		t.Errorf("Expected invalid output to fail, got %d instead", code)
+	}
+
+	if code := run(context.Background(), []string{
+		"--filter", "year", "books",
+	}, &stdout, &stderr); code != 1 {
+		t.Errorf("Expected filter without operator to fail, got %d instead", code)
+	}
+}
+
+func TestParseCondition(t *testing.T) {
+	var cases = []struct {
+		condition string
+		want      *filter.Filter
+	}{
+		{"year>1600", filter.New("year", ">", 1600.0)},
+		{"year >= 1600", filter.New("year", ">=", 1600.0)},
+		{"year<=1610", filter.Lte("year", 1610.0)},
+		{"title=Hamlet", filter.Equal("title", "Hamlet")},
+		{"title=\"1603\"", filter.Equal("title", "1603")},
+		{"genre!=comedy", filter.New("genre", "!=", "comedy")},
+		{"read=true", filter.Equal("read", true)},
+		{"title~^Ham", filter.New("title", "~", "^Ham")},
+		{"url=http://a?b=c", filter.Equal("url", "http://a?b=c")},
+		{"hamlet", nil},
+	}
+
+	for _, c := range cases {
+		var got, err = parseCondition(c.condition)
+
+		if err != nil {
+			t.Errorf("Expected no error parsing %v, got %v instead", c.condition, err)
+		}
+
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("Expected %v to be %+v, got %+v instead", c.condition, c.want, got)
+		}
+	}
+}
+
+func TestParseAggregation(t *testing.T) {
+	var a, err = parseAggregation("avg_year:year:avg")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var bin, _ = json.Marshal(a)
+	var want = `{"year":{"name":"avg_year","operator":"avg"}}`
+
+	if string(bin) != want {
+		t.Errorf("Expected aggregation %v, got %s instead", want, bin)
+	}
+
+	if _, err = parseAggregation("year:avg"); err == nil {
+		t.Errorf("Expected error for aggregation without operator")
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// printTable prints a list of documents with a column per field,
+// an object with a line per key, or else the value itself
+func printTable(stdout io.Writer, body []byte) error {
+	var v interface{}
+	var d = json.NewDecoder(bytes.NewReader(body))
+	d.UseNumber()
+
+	if err := d.Decode(&v); err != nil {
+		return errors.New("response is not JSON")
+	}
+
+	var tw = tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+
+	switch t := v.(type) {
+	case []interface{}:
+		printRows(tw, t)
+	case map[string]interface{}:
+		for _, key := range sortedKeys(t) {
+			fmt.Fprintf(tw, "%s\t%s\n", key, cell(t[key]))
+		}
+	default:
+		fmt.Fprintln(tw, cell(t))
+	}
+
+	return tw.Flush()
+}
+
+func printRows(tw io.Writer, rows []interface{}) {
+	var columns = map[string]interface{}{}
+
+	for _, row := range rows {
+		if m, ok := row.(map[string]interface{}); ok {
+			for key := range m {
+				columns[key] = true
+			}
+		}
+	}
+
+	if len(columns) == 0 {
+		for _, row := range rows {
+			fmt.Fprintln(tw, cell(row))
+		}
+
+		return
+	}
+
+	var header = sortedKeys(columns)
+	fmt.Fprintln(tw, strings.ToUpper(strings.Join(header, "\t")))
+
+	for _, row := range rows {
+		var m, _ = row.(map[string]interface{})
+		var cells = make([]string, len(header))
+
+		for i, key := range header {
+			cells[i] = cell(m[key])
+		}
+
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+}
+
+// sortedKeys of a map, with id first
+func sortedKeys(m map[string]interface{}) []string {
+	var keys []string
+	var hasID bool
+
+	for key := range m {
+		if key == "id" {
+			hasID = true
+		} else {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	if hasID {
+		keys = append([]string{"id"}, keys...)
+	}
+
+	return keys
+}
+
+// cell writes strings as is, and other values as JSON
+func cell(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	}
+
+	var bin, _ = json.Marshal(v)
+	return string(bin)
+}
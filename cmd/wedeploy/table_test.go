@@ -0,0 +1,49 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPrintTable(t *testing.T) {
+	var cases = []struct {
+		body string
+		want string
+	}{
+		{
+			`[{"title":"Hamlet","id":"1","tags":["drama"]},{"id":"2","year":1623}]`,
+			"ID  TAGS       TITLE   YEAR\n" +
+				"1   [\"drama\"]  Hamlet  \n" +
+				"2                      1623\n",
+		},
+		{
+			`{"total":3,"avg_year":{"value":1611.67}}`,
+			"avg_year  {\"value\":1611.67}\n" +
+				"total     3\n",
+		},
+		{`[1,"two"]`, "1\ntwo\n"},
+		{`42`, "42\n"},
+	}
+
+	for _, c := range cases {
+		var b bytes.Buffer
+
+		if err := printTable(&b, []byte(c.body)); err != nil {
+			t.Errorf("Expected no error printing %v, got %v instead", c.body, err)
+		}
+
+		if b.String() != c.want {
+			t.Errorf("Expected table %q, got %q instead", c.want, b.String())
+		}
+	}
+}
+
+func TestPrintTableNotJSON(t *testing.T) {
+	if err := printTable(&bytes.Buffer{}, []byte("<html>")); err == nil {
+		t.Errorf("Expected error printing a body that isn't JSON")
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wedeploy
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// DefaultCompressionMinSize is the minimum size of the request bodies
+// compressed by default, in bytes
+const DefaultCompressionMinSize = 1024
+
+// CompressionSettings of the client.
+// Only gzip is supported.
+type CompressionSettings struct {
+	// Requests compresses the request bodies with gzip.
+	// Bodies that aren't already encoded are read into memory to be
+	// compressed, and sent with the Content-Encoding: gzip header.
+	Requests bool
+
+	// MinSize of the request bodies compressed
+	// (DefaultCompressionMinSize, if zero)
+	MinSize int
+
+	// Level of the gzip compression, from gzip.BestSpeed to
+	// gzip.BestCompression (gzip.DefaultCompression, if zero)
+	Level int
+
+	// Responses asks for gzip responses with the Accept-Encoding header
+	// and decompresses them, regardless of the configuration of the
+	// transport of the HTTP client. Requests with their own Accept-Encoding
+	// header get the response as it is.
+	Responses bool
+}
+
+// WithCompression enables the compression of request and response bodies
+func WithCompression(s CompressionSettings) Option {
+	if s.MinSize == 0 {
+		s.MinSize = DefaultCompressionMinSize
+	}
+
+	if s.Level == 0 {
+		s.Level = gzip.DefaultCompression
+	}
+
+	return func(h *HTTPClient) {
+		h.compression = &s
+	}
+}
+
+// compressBody compresses the request body, if it is large enough,
+// returning the body to send and, if it was compressed, the uncompressed body
+func (s *CompressionSettings) compressBody(body io.Reader, header http.Header) (io.Reader, []byte, error) {
+	if body == nil || !s.Requests || header.Get("Content-Encoding") != "" {
+		return body, nil, nil
+	}
+
+	var bin, err = readBody(body)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(bin) < s.MinSize {
+		return bytes.NewReader(bin), nil, nil
+	}
+
+	var b bytes.Buffer
+	gz, err := gzip.NewWriterLevel(&b, s.Level)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if _, err := gz.Write(bin); err != nil {
+		return nil, nil, err
+	}
+
+	if err := gz.Close(); err != nil {
+		return nil, nil, err
+	}
+
+	return bytes.NewReader(b.Bytes()), append([]byte{}, bin...), nil
+}
+
+// readBody without consuming a *bytes.Buffer, which is sent again on
+// the next request
+func readBody(body io.Reader) ([]byte, error) {
+	if bb, ok := body.(*bytes.Buffer); ok {
+		return bb.Bytes(), nil
+	}
+
+	return ioutil.ReadAll(body)
+}
+
+// wrap asks for gzip responses and decompresses them
+func (s *CompressionSettings) wrap(next roundTrip) roundTrip {
+	return func(req *http.Request) (*http.Response, error) {
+		if !s.Responses || req.Header.Get("Accept-Encoding") != "" {
+			return next(req)
+		}
+
+		var r = *req
+		r.Header = cloneHeader(req.Header)
+		r.Header.Set("Accept-Encoding", "gzip")
+
+		var resp, err = next(&r)
+
+		if err != nil || !strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+			return resp, err
+		}
+
+		resp.Body = &gzipBody{body: resp.Body}
+		resp.Header.Del("Content-Encoding")
+		resp.Header.Del("Content-Length")
+		resp.ContentLength = -1
+		resp.Uncompressed = true
+		return resp, nil
+	}
+}
+
+// gzipBody decompresses a response body on the first read,
+// so that an empty body of a HEAD request isn't an error
+type gzipBody struct {
+	body io.ReadCloser
+	zr   *gzip.Reader
+	err  error
+}
+
+func (g *gzipBody) Read(p []byte) (int, error) {
+	if g.zr == nil && g.err == nil {
+		g.zr, g.err = gzip.NewReader(g.body)
+	}
+
+	if g.err != nil {
+		return 0, g.err
+	}
+
+	return g.zr.Read(p)
+}
+
+func (g *gzipBody) Close() error {
+	return g.body.Close()
+}
@@ -0,0 +1,303 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wedeploy
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/henvic/wedeploy-sdk-go/filter"
+)
+
+type receivedRequest struct {
+	encoding string
+	body     string
+}
+
+func setupCompressionServer(received *[]receivedRequest) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var bin, _ = ioutil.ReadAll(r.Body)
+		var encoding = r.Header.Get("Content-Encoding")
+
+		if encoding == "gzip" {
+			var zr, err = gzip.NewReader(bytes.NewReader(bin))
+
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			bin, _ = ioutil.ReadAll(zr)
+		}
+
+		*received = append(*received, receivedRequest{encoding, string(bin)})
+
+		var body = []byte(`{"title":"` + strings.Repeat("a", 2000) + `"}`)
+
+		if r.Header.Get("Accept-Encoding") == "gzip" {
+			var b bytes.Buffer
+			var gz = gzip.NewWriter(&b)
+			gz.Write(body)
+			gz.Close()
+			body = b.Bytes()
+			w.Header().Set("Content-Encoding", "gzip")
+		}
+
+		w.Write(body)
+	}))
+}
+
+func TestCompressRequest(t *testing.T) {
+	var received []receivedRequest
+	var s = setupCompressionServer(&received)
+	defer s.Close()
+
+	var client = NewHTTPClient(WithCompression(CompressionSettings{Requests: true}))
+	var req = client.URL(s.URL)
+
+	for i := 0; i < 300; i++ {
+		req.Filter(filter.Equal("id", i))
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := req.Post(); err != nil {
+			t.Fatal(err)
+		}
+
+		req.Response.Body.Close()
+	}
+
+	if len(received) != 2 {
+		t.Fatalf("Expected 2 requests, got %v instead", len(received))
+	}
+
+	var bin, _ = json.Marshal(req.Query)
+	var want = receivedRequest{"gzip", string(bin)}
+
+	for _, r := range received {
+		if r != want {
+			t.Errorf("Expected request %v, got %v instead", want, r)
+		}
+	}
+
+	if req.Headers.Get("Content-Encoding") != "" {
+		t.Errorf("Expected request headers to be unchanged")
+	}
+
+	if req.Request.ContentLength >= int64(len(want.body)) {
+		t.Errorf("Expected compressed body, got %v bytes", req.Request.ContentLength)
+	}
+}
+
+func TestCompressRequestBufferSentTwice(t *testing.T) {
+	var received []receivedRequest
+	var s = setupCompressionServer(&received)
+	defer s.Close()
+
+	var client = NewHTTPClient(WithCompression(CompressionSettings{Requests: true}))
+	var body = `{"title":"` + strings.Repeat("a", 2000) + `"}`
+
+	for _, b := range []string{body, `{"id":"1"}`} {
+		received = nil
+		var req = client.URL(s.URL).Body(bytes.NewBufferString(b))
+
+		for i := 0; i < 2; i++ {
+			if err := req.Post(); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if len(received) != 2 || received[0].body != b || received[1].body != b {
+			t.Errorf("Expected body to be sent twice, got %v instead", received)
+		}
+	}
+}
+
+func TestCompressRequestDumpAndLog(t *testing.T) {
+	var received []receivedRequest
+	var s = setupCompressionServer(&received)
+	defer s.Close()
+
+	var logger = &memoryLogger{}
+	var client = NewHTTPClient(
+		WithCompression(CompressionSettings{Requests: true, MinSize: 1}),
+		WithLogger(logger, LogSettings{Bodies: true, SensitiveFields: []string{"password"}, MaxBodySize: 1024}))
+
+	var body = `{"name":"Ophelia","password":"p4ss"}`
+	var req = client.URL(s.URL).Body(bytes.NewBufferString(body))
+
+	var curl, err = req.Curl("POST")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasSuffix(curl, " --data-binary '"+body+"'") || strings.Contains(curl, "Content-Encoding") {
+		t.Errorf("Expected curl command with uncompressed body, got %q instead", curl)
+	}
+
+	dump, err := req.Dump("POST")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasSuffix(string(dump), "\r\n\r\n"+body) ||
+		!strings.Contains(string(dump), fmt.Sprintf("Content-Length: %d\r\n", len(body))) ||
+		strings.Contains(string(dump), "Content-Encoding") {
+		t.Errorf("Expected dump with uncompressed body, got %q instead", dump)
+	}
+
+	if err := req.Post(); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := (receivedRequest{"gzip", body}); len(received) != 1 || received[0] != want {
+		t.Errorf("Expected request %v, got %v instead", want, received)
+	}
+
+	var want = `{"name":"Ophelia","password":"REDACTED"}`
+
+	if got := logger.entries[0].fields["body"]; got != want {
+		t.Errorf("Expected logged body %v, got %q instead", want, got)
+	}
+}
+
+func TestCompressRequestSmallBody(t *testing.T) {
+	var received []receivedRequest
+	var s = setupCompressionServer(&received)
+	defer s.Close()
+
+	var client = NewHTTPClient(WithCompression(CompressionSettings{Requests: true}))
+
+	if err := client.URL(s.URL).Body(strings.NewReader(`{"id":"1"}`)).Post(); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := (receivedRequest{"", `{"id":"1"}`}); received[0] != want {
+		t.Errorf("Expected request %v, got %v instead", want, received[0])
+	}
+}
+
+func TestCompressRequestEncoded(t *testing.T) {
+	var received []receivedRequest
+	var s = setupCompressionServer(&received)
+	defer s.Close()
+
+	var client = NewHTTPClient(WithCompression(CompressionSettings{Requests: true, MinSize: 1}))
+
+	var b bytes.Buffer
+	var gz = gzip.NewWriter(&b)
+	gz.Write([]byte(`{"id":"1"}`))
+	gz.Close()
+
+	var req = client.URL(s.URL).Body(&b)
+	req.Header("Content-Encoding", "gzip")
+
+	if err := req.Post(); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := (receivedRequest{"gzip", `{"id":"1"}`}); received[0] != want {
+		t.Errorf("Expected request %v, got %v instead", want, received[0])
+	}
+}
+
+func TestDecompressResponse(t *testing.T) {
+	var received []receivedRequest
+	var s = setupCompressionServer(&received)
+	defer s.Close()
+
+	// the transport wouldn't decompress the response by itself
+	var client = NewHTTPClient(
+		WithHTTP(&http.Client{Transport: &http.Transport{DisableCompression: true}}),
+		WithCompression(CompressionSettings{Responses: true}))
+
+	var req = client.URL(s.URL)
+
+	if err := req.Get(); err != nil {
+		t.Fatal(err)
+	}
+
+	if req.Response.Header.Get("Content-Encoding") != "" {
+		t.Errorf("Expected Content-Encoding to be removed")
+	}
+
+	if req.Headers.Get("Accept-Encoding") != "" {
+		t.Errorf("Expected request headers to be unchanged")
+	}
+
+	assertBody(t, `{"title":"`+strings.Repeat("a", 2000)+`"}`, req.Response.Body)
+}
+
+func TestDecompressResponseLimit(t *testing.T) {
+	var received []receivedRequest
+	var s = setupCompressionServer(&received)
+	defer s.Close()
+
+	var client = NewHTTPClient(
+		WithCompression(CompressionSettings{Responses: true}),
+		WithMaxResponseSize(1000))
+
+	var req = client.URL(s.URL)
+
+	if err := req.Get(); err != nil {
+		t.Fatal(err)
+	}
+
+	var v map[string]interface{}
+
+	if err := req.DecodeJSON(&v); err != (ErrResponseTooLarge{1000}) {
+		t.Errorf("Expected decompressed body to be limited, got %v instead", err)
+	}
+}
+
+func TestDecompressResponseExplicitAcceptEncoding(t *testing.T) {
+	var received []receivedRequest
+	var s = setupCompressionServer(&received)
+	defer s.Close()
+
+	var client = NewHTTPClient(WithCompression(CompressionSettings{Responses: true}))
+	var req = client.URL(s.URL)
+	req.Header("Accept-Encoding", "gzip")
+
+	if err := req.Get(); err != nil {
+		t.Fatal(err)
+	}
+
+	if req.Response.Header.Get("Content-Encoding") != "gzip" {
+		t.Errorf("Expected response to be kept compressed")
+	}
+
+	var zr, err = gzip.NewReader(req.Response.Body)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertBody(t, `{"title":"`+strings.Repeat("a", 2000)+`"}`, ioutil.NopCloser(zr))
+}
+
+func TestDecompressResponseHead(t *testing.T) {
+	var received []receivedRequest
+	var s = setupCompressionServer(&received)
+	defer s.Close()
+
+	var client = NewHTTPClient(WithCompression(CompressionSettings{Responses: true}))
+	var req = client.URL(s.URL)
+
+	if err := req.Head(); err != nil {
+		t.Fatal(err)
+	}
+
+	assertTextualBody(t, "", req.Response.Body)
+}
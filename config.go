@@ -0,0 +1,443 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wedeploy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config of the SDK, loaded by LoadConfig
+type Config struct {
+	Profile   string
+	URL       string
+	Project   string
+	Token     string
+	Username  string
+	Password  string
+	UserAgent string
+	Timeout   time.Duration
+	Headers   map[string]string
+}
+
+// configFile is the structure of a JSON or YAML profile file:
+//
+//	default: production
+//	profiles:
+//	  production:
+//	    url: https://data-example.wedeploy.io
+//	    token: secret
+//	    timeout: 30s
+type configFile struct {
+	Default  string                   `json:"default"`
+	Profiles map[string]configProfile `json:"profiles"`
+}
+
+type configProfile struct {
+	URL       string            `json:"url"`
+	Project   string            `json:"project"`
+	Token     string            `json:"token"`
+	Username  string            `json:"username"`
+	Password  string            `json:"password"`
+	UserAgent string            `json:"user_agent"`
+	Timeout   configTimeout     `json:"timeout"`
+	Headers   map[string]string `json:"headers"`
+}
+
+// configTimeout is a duration such as "30s", or a number of seconds
+// given as a number or string
+type configTimeout string
+
+func (c *configTimeout) UnmarshalJSON(data []byte) error {
+	var s string
+
+	if err := json.Unmarshal(data, &s); err == nil {
+		*c = configTimeout(s)
+		return nil
+	}
+
+	var n json.Number
+
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("invalid timeout %s", data)
+	}
+
+	*c = configTimeout(n)
+	return nil
+}
+
+// DefaultProfile is used when no profile is selected
+const DefaultProfile = "default"
+
+var lookupEnv = os.LookupEnv
+
+// LoadConfig loads the configuration from a profile file and from the
+// WEDEPLOY_* environment variables, which take precedence over the file.
+//
+// The file path is given by the file argument, or else by WEDEPLOY_CONFIG.
+// It is optional, and is parsed as YAML if it has a .yml or .yaml extension,
+// and as JSON otherwise.
+//
+// The profile is given by the profile argument, or else by WEDEPLOY_PROFILE,
+// or else by the default key of the file. Selecting a profile that
+// doesn't exist on the file is an error.
+//
+// The environment variables are WEDEPLOY_URL, WEDEPLOY_PROJECT,
+// WEDEPLOY_TOKEN, WEDEPLOY_USERNAME, WEDEPLOY_PASSWORD,
+// WEDEPLOY_USER_AGENT, and WEDEPLOY_TIMEOUT (such as 30s).
+func LoadConfig(file, profile string) (*Config, error) {
+	if file == "" {
+		file, _ = lookupEnv("WEDEPLOY_CONFIG")
+	}
+
+	if profile == "" {
+		profile, _ = lookupEnv("WEDEPLOY_PROFILE")
+	}
+
+	var c = &Config{}
+
+	if file != "" {
+		if err := c.loadFile(file, profile); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.Profile == "" {
+		c.Profile = profile
+	}
+
+	if c.Profile == "" {
+		c.Profile = DefaultProfile
+	}
+
+	if err := c.loadEnv(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// NewHTTPClientFromConfig creates a client with the configuration loaded
+// by LoadConfig. Options are applied after the configuration.
+func NewHTTPClientFromConfig(file, profile string, opts ...Option) (*HTTPClient, error) {
+	var c, err = LoadConfig(file, profile)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return c.HTTPClient(opts...), nil
+}
+
+// BaseURL of the configuration: the URL, or else the project data URL
+func (c *Config) BaseURL() string {
+	if c.URL == "" && c.Project != "" {
+		return "https://data-" + c.Project + ".wedeploy.io"
+	}
+
+	return c.URL
+}
+
+// HTTPClient creates a client with the configuration.
+// Options are applied after the configuration.
+func (c *Config) HTTPClient(opts ...Option) *HTTPClient {
+	var o = []Option{
+		WithBaseURL(c.BaseURL()),
+		WithHTTP(&http.Client{Timeout: c.Timeout}),
+	}
+
+	switch {
+	case c.Token != "":
+		o = append(o, WithAuth(c.Token))
+	case c.Username != "" || c.Password != "":
+		o = append(o, WithAuth(c.Username, c.Password))
+	}
+
+	if c.UserAgent != "" {
+		o = append(o, WithUserAgent(c.UserAgent))
+	}
+
+	for _, key := range c.headerKeys() {
+		o = append(o, WithHeader(key, c.Headers[key]))
+	}
+
+	return NewHTTPClient(append(o, opts...)...)
+}
+
+// String representation of the configuration with credentials redacted,
+// so it is safe to be logged
+func (c Config) String() string {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "profile=%q url=%q project=%q", c.Profile, c.URL, c.Project)
+	fmt.Fprintf(&b, " token=%q username=%q password=%q",
+		redact(c.Token), c.Username, redact(c.Password))
+	fmt.Fprintf(&b, " user_agent=%q timeout=%v", c.UserAgent, c.Timeout)
+
+	if len(c.Headers) != 0 {
+		b.WriteString(" headers=[")
+
+		for i, key := range c.headerKeys() {
+			if i != 0 {
+				b.WriteString(" ")
+			}
+
+			fmt.Fprintf(&b, "%s=%q", key, redact(c.Headers[key]))
+		}
+
+		b.WriteString("]")
+	}
+
+	return b.String()
+}
+
+func (c *Config) headerKeys() []string {
+	var keys []string
+
+	for key := range c.Headers {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+func (c *Config) loadFile(file, profile string) error {
+	var bin, err = ioutil.ReadFile(file)
+
+	if err != nil {
+		return err
+	}
+
+	var f configFile
+
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yml", ".yaml":
+		err = unmarshalYAML(bin, &f)
+	default:
+		err = json.Unmarshal(bin, &f)
+	}
+
+	if err != nil {
+		return fmt.Errorf("can't parse config file %v: %v", file, err)
+	}
+
+	var explicit = profile != ""
+
+	if !explicit {
+		profile = f.Default
+	}
+
+	if profile == "" {
+		profile = DefaultProfile
+	}
+
+	var p, ok = f.Profiles[profile]
+
+	if !ok && (explicit || f.Default != "") {
+		return fmt.Errorf("profile %q not found on config file %v", profile, file)
+	}
+
+	c.Profile = profile
+	return c.apply(p)
+}
+
+func (c *Config) apply(p configProfile) error {
+	c.URL = p.URL
+	c.Project = p.Project
+	c.Token = p.Token
+	c.Username = p.Username
+	c.Password = p.Password
+	c.UserAgent = p.UserAgent
+	c.Headers = p.Headers
+
+	if p.Timeout == "" {
+		return nil
+	}
+
+	var err error
+	c.Timeout, err = parseTimeout(string(p.Timeout))
+	return err
+}
+
+func (c *Config) loadEnv() error {
+	var vars = map[string]*string{
+		"WEDEPLOY_URL":        &c.URL,
+		"WEDEPLOY_PROJECT":    &c.Project,
+		"WEDEPLOY_TOKEN":      &c.Token,
+		"WEDEPLOY_USERNAME":   &c.Username,
+		"WEDEPLOY_PASSWORD":   &c.Password,
+		"WEDEPLOY_USER_AGENT": &c.UserAgent,
+	}
+
+	for key, field := range vars {
+		if value, ok := lookupEnv(key); ok {
+			*field = value
+		}
+	}
+
+	if value, ok := lookupEnv("WEDEPLOY_TIMEOUT"); ok {
+		var err error
+
+		if c.Timeout, err = parseTimeout(value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseTimeout parses a duration such as 30s, or a number of seconds
+func parseTimeout(s string) (time.Duration, error) {
+	if seconds, err := strconv.ParseFloat(s, 64); err == nil {
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+
+	var d, err = time.ParseDuration(s)
+
+	if err != nil {
+		return 0, fmt.Errorf("invalid timeout %q", s)
+	}
+
+	return d, nil
+}
+
+func redact(s string) string {
+	if s == "" {
+		return ""
+	}
+
+	return "REDACTED"
+}
+
+// unmarshalYAML decodes the subset of YAML used by config files:
+// nested mappings of scalars, with comments and quoted strings
+func unmarshalYAML(data []byte, v interface{}) error {
+	var m, err = parseYAML(data)
+
+	if err != nil {
+		return err
+	}
+
+	bin, err := json.Marshal(m)
+
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(bin, v)
+}
+
+type yamlLevel struct {
+	indent int
+	m      map[string]interface{}
+}
+
+// parseYAML parses the subset of YAML of unmarshalYAML, rejecting any
+// indentation that doesn't open a mapping or go back to an open one
+func parseYAML(data []byte) (map[string]interface{}, error) {
+	var root = map[string]interface{}{}
+	var stack = []yamlLevel{{0, root}}
+	var pending string
+
+	for n, line := range strings.Split(string(data), "\n") {
+		var content = strings.TrimRight(yamlStripComment(line), " \t\r")
+		var trimmed = strings.TrimLeft(content, " ")
+
+		if trimmed == "" || trimmed == "---" {
+			continue
+		}
+
+		var indent = len(content) - len(trimmed)
+
+		if strings.HasPrefix(trimmed, "-") || strings.Contains(content, "\t") {
+			return nil, fmt.Errorf("line %d: unsupported YAML", n+1)
+		}
+
+		if pending != "" {
+			var parent = stack[len(stack)-1]
+
+			if indent > parent.indent {
+				var m = map[string]interface{}{}
+				parent.m[pending] = m
+				stack = append(stack, yamlLevel{indent, m})
+			} else {
+				parent.m[pending] = nil
+			}
+
+			pending = ""
+		}
+
+		for indent < stack[len(stack)-1].indent {
+			stack = stack[:len(stack)-1]
+		}
+
+		if indent != stack[len(stack)-1].indent {
+			return nil, fmt.Errorf("line %d: bad indentation", n+1)
+		}
+
+		var i = strings.Index(trimmed, ":")
+
+		if i <= 0 {
+			return nil, fmt.Errorf("line %d: expected key: value", n+1)
+		}
+
+		var key = yamlUnquote(strings.TrimSpace(trimmed[:i]))
+		var value = strings.TrimSpace(trimmed[i+1:])
+
+		if value == "" {
+			pending = key
+			continue
+		}
+
+		stack[len(stack)-1].m[key] = yamlUnquote(value)
+	}
+
+	if pending != "" {
+		stack[len(stack)-1].m[pending] = nil
+	}
+
+	return root, nil
+}
+
+func yamlStripComment(line string) string {
+	var quote rune
+
+	for i, r := range line {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote == 0 && (r == '"' || r == '\''):
+			quote = r
+		case quote == 0 && r == '#' && (i == 0 || line[i-1] == ' '):
+			return line[:i]
+		}
+	}
+
+	return line
+}
+
+func yamlUnquote(s string) string {
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		if u, err := strconv.Unquote(s); err == nil {
+			return u
+		}
+	}
+
+	if len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'' {
+		return strings.Replace(s[1:len(s)-1], "''", "'", -1)
+	}
+
+	return s
+}
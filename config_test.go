@@ -0,0 +1,381 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wedeploy
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var configJSON = `{
+    "default": "staging",
+    "profiles": {
+        "staging": {
+            "url": "https://data-staging.wedeploy.io",
+            "token": "staging-token",
+            "timeout": "10s"
+        },
+        "production": {
+            "project": "example",
+            "username": "admin",
+            "password": "safe",
+            "user_agent": "my-app/1.0",
+            "timeout": 30,
+            "headers": {"X-Tenant": "liferay"}
+        }
+    }
+}`
+
+var configYAML = `# WeDeploy profiles
+default: staging
+profiles:
+  staging:
+    url: https://data-staging.wedeploy.io # staging
+    token: "staging-token"
+    timeout: 10s
+  production:
+    project: 'example'
+    username: admin
+    password: "safe#1"
+    user_agent: my-app/1.0
+    timeout: 30
+    headers:
+      X-Tenant: liferay
+`
+
+func TestLoadConfigJSON(t *testing.T) {
+	defer setupEnv(nil)()
+	var file, cleanup = writeConfigFile(t, "config.json", configJSON)
+	defer cleanup()
+
+	var c, err = LoadConfig(file, "")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var want = &Config{
+		Profile: "staging",
+		URL:     "https://data-staging.wedeploy.io",
+		Token:   "staging-token",
+		Timeout: 10 * time.Second,
+	}
+
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("Expected config %+v, got %+v instead", want, c)
+	}
+}
+
+func TestLoadConfigJSONTimeout(t *testing.T) {
+	defer setupEnv(nil)()
+
+	var cases = map[string]time.Duration{
+		`30`:    30 * time.Second,
+		`1.5`:   1500 * time.Millisecond,
+		`"30"`:  30 * time.Second,
+		`"1m"`:  time.Minute,
+		`"0.5"`: 500 * time.Millisecond,
+	}
+
+	for timeout, want := range cases {
+		var file, cleanup = writeConfigFile(t, "config.json",
+			`{"profiles": {"default": {"timeout": `+timeout+`}}}`)
+
+		var c, err = LoadConfig(file, "")
+		cleanup()
+
+		if err != nil || c.Timeout != want {
+			t.Errorf("Expected timeout %v to be %v, got %v (%v) instead", timeout, want, c, err)
+		}
+	}
+
+	var file, cleanup = writeConfigFile(t, "config.json", `{"profiles": {"default": {"timeout": true}}}`)
+	defer cleanup()
+
+	if _, err := LoadConfig(file, ""); err == nil {
+		t.Errorf("Expected error for invalid timeout")
+	}
+}
+
+func TestLoadConfigYAML(t *testing.T) {
+	defer setupEnv(nil)()
+	var file, cleanup = writeConfigFile(t, "config.yml", configYAML)
+	defer cleanup()
+
+	var c, err = LoadConfig(file, "production")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var want = &Config{
+		Profile:   "production",
+		Project:   "example",
+		Username:  "admin",
+		Password:  "safe#1",
+		UserAgent: "my-app/1.0",
+		Timeout:   30 * time.Second,
+		Headers:   map[string]string{"X-Tenant": "liferay"},
+	}
+
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("Expected config %+v, got %+v instead", want, c)
+	}
+
+	if c.BaseURL() != "https://data-example.wedeploy.io" {
+		t.Errorf("Unexpected base URL %v", c.BaseURL())
+	}
+}
+
+func TestLoadConfigEnvPrecedence(t *testing.T) {
+	var file, cleanup = writeConfigFile(t, "config.json", configJSON)
+	defer cleanup()
+
+	defer setupEnv(map[string]string{
+		"WEDEPLOY_CONFIG":  file,
+		"WEDEPLOY_PROFILE": "production",
+		"WEDEPLOY_URL":     "http://localhost:8080",
+		"WEDEPLOY_TOKEN":   "env-token",
+		"WEDEPLOY_TIMEOUT": "1m",
+	})()
+
+	var c, err = LoadConfig("", "")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Profile != "production" ||
+		c.URL != "http://localhost:8080" ||
+		c.Token != "env-token" ||
+		c.Username != "admin" ||
+		c.Timeout != time.Minute {
+		t.Errorf("Unexpected config %+v", c)
+	}
+
+	// the argument takes precedence over WEDEPLOY_PROFILE
+	if c, err = LoadConfig("", "staging"); err != nil || c.Profile != "staging" {
+		t.Errorf("Expected staging profile, got %+v (%v) instead", c, err)
+	}
+}
+
+func TestLoadConfigEnvOnly(t *testing.T) {
+	defer setupEnv(map[string]string{
+		"WEDEPLOY_PROJECT":  "example",
+		"WEDEPLOY_USERNAME": "admin",
+		"WEDEPLOY_PASSWORD": "safe",
+	})()
+
+	var c, err = LoadConfig("", "")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var want = &Config{
+		Profile:  DefaultProfile,
+		Project:  "example",
+		Username: "admin",
+		Password: "safe",
+	}
+
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("Expected config %+v, got %+v instead", want, c)
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	defer setupEnv(nil)()
+	var file, cleanup = writeConfigFile(t, "config.json", configJSON)
+	defer cleanup()
+
+	if _, err := LoadConfig(file, "missing"); err == nil {
+		t.Errorf("Expected error for missing profile")
+	}
+
+	if _, err := LoadConfig(filepath.Join(filepath.Dir(file), "nope.json"), ""); err == nil {
+		t.Errorf("Expected error for missing file")
+	}
+
+	var invalid, cleanupInvalid = writeConfigFile(t, "invalid.yaml", "profiles:\n  - foo\n")
+	defer cleanupInvalid()
+
+	if _, err := LoadConfig(invalid, ""); err == nil {
+		t.Errorf("Expected error for invalid file")
+	}
+
+	defer setupEnv(map[string]string{"WEDEPLOY_TIMEOUT": "soon"})()
+
+	if _, err := LoadConfig("", ""); err == nil {
+		t.Errorf("Expected error for invalid timeout")
+	}
+}
+
+func TestParseYAML(t *testing.T) {
+	var got, err = parseYAML([]byte("a:\n  b:\n    c: 1\n  d: 2\ne:\nf: 'x'\n"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var want = map[string]interface{}{
+		"a": map[string]interface{}{
+			"b": map[string]interface{}{"c": "1"},
+			"d": "2",
+		},
+		"e": nil,
+		"f": "x",
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v instead", want, got)
+	}
+}
+
+func TestParseYAMLErrors(t *testing.T) {
+	var cases = []string{
+		"  a:\nb: 1\n",
+		" a: 1\n",
+		"a: 1\n  b: 2\n",
+		"profiles:\n  staging:\n    url: x\n token: y\n",
+		"a:\n    b: 1\n  c: 2\n",
+		"a:\n  b:\n    c: 1\n   d: 2\n",
+		"a:\n\tb: 1\n",
+		"a\n",
+		"- a\n",
+	}
+
+	for _, c := range cases {
+		if m, err := parseYAML([]byte(c)); err == nil {
+			t.Errorf("Expected error parsing %q, got %v instead", c, m)
+		}
+	}
+}
+
+func TestConfigHTTPClient(t *testing.T) {
+	var c = &Config{
+		Project:   "example",
+		Username:  "admin",
+		Password:  "safe",
+		UserAgent: "my-app/1.0",
+		Timeout:   5 * time.Second,
+		Headers:   map[string]string{"X-Tenant": "liferay"},
+	}
+
+	var hc = c.HTTPClient(WithHeader("X-Extra", "1"))
+	var req = hc.URL("books")
+
+	assertURI(t, "https://data-example.wedeploy.io/books", req.URL)
+
+	if hc.HTTP().Timeout != 5*time.Second {
+		t.Errorf("Expected timeout to be set, got %v", hc.HTTP().Timeout)
+	}
+
+	var headers = map[string]string{
+		"Authorization": "Basic YWRtaW46c2FmZQ==",
+		"User-Agent":    "my-app/1.0",
+		"X-Tenant":      "liferay",
+		"X-Extra":       "1",
+	}
+
+	for key, want := range headers {
+		if got := req.Headers.Get(key); got != want {
+			t.Errorf("Expected header %s=%s, got %s instead", key, want, got)
+		}
+	}
+
+	c.Token = "token"
+
+	if got := c.HTTPClient().URL("x").Headers.Get("Authorization"); got != "Bearer token" {
+		t.Errorf("Expected token to take precedence, got %v instead", got)
+	}
+}
+
+func TestNewHTTPClientFromConfig(t *testing.T) {
+	defer setupEnv(nil)()
+	var file, cleanup = writeConfigFile(t, "config.json", configJSON)
+	defer cleanup()
+
+	var hc, err = NewHTTPClientFromConfig(file, "production", WithHeader("X-Extra", "1"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var req = hc.URL("books")
+
+	assertURI(t, "https://data-example.wedeploy.io/books", req.URL)
+
+	if hc.HTTP().Timeout != 30*time.Second || req.Headers.Get("X-Extra") != "1" ||
+		req.Headers.Get("X-Tenant") != "liferay" {
+		t.Errorf("Expected client with the configuration, got %+v", req.Headers)
+	}
+
+	if _, err := NewHTTPClientFromConfig(file, "missing"); err == nil {
+		t.Errorf("Expected error for missing profile")
+	}
+}
+
+func TestConfigString(t *testing.T) {
+	var c = Config{
+		Profile:  "production",
+		URL:      "https://data-example.wedeploy.io",
+		Token:    "secret-token",
+		Username: "admin",
+		Password: "secret-password",
+		Timeout:  time.Second,
+		Headers:  map[string]string{"X-Api-Key": "secret-key"},
+	}
+
+	var got = c.String()
+
+	if strings.Contains(got, "secret") {
+		t.Errorf("Expected credentials to be redacted, got %v", got)
+	}
+
+	var want = `profile="production" url="https://data-example.wedeploy.io" project="" ` +
+		`token="REDACTED" username="admin" password="REDACTED" user_agent="" timeout=1s ` +
+		`headers=[X-Api-Key="REDACTED"]`
+
+	if got != want {
+		t.Errorf("Expected %v, got %v instead", want, got)
+	}
+}
+
+func setupEnv(env map[string]string) func() {
+	var original = lookupEnv
+
+	lookupEnv = func(key string) (string, bool) {
+		var value, ok = env[key]
+		return value, ok
+	}
+
+	return func() {
+		lookupEnv = original
+	}
+}
+
+func writeConfigFile(t *testing.T, name, content string) (string, func()) {
+	var dir, err = ioutil.TempDir("", "wedeploy-config")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var file = filepath.Join(dir, name)
+
+	if err = ioutil.WriteFile(file, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return file, func() {
+		os.RemoveAll(dir)
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wedeploy
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// WithDeduplication coalesces identical GET and HEAD requests sent
+// concurrently by the client: only the first one is sent, and every
+// caller gets its own copy of its response.
+// Requests are identical if they have the same method, URL, canonical
+// query, credentials (the Authorization and Cookie headers), and the
+// DefaultVaryHeaders and given headers.
+func WithDeduplication(headers ...string) Option {
+	var all []string
+	var seen = map[string]bool{}
+
+	for _, name := range append(append([]string{"Authorization", "Cookie"}, DefaultVaryHeaders...), headers...) {
+		if name = http.CanonicalHeaderKey(name); !seen[name] {
+			seen[name] = true
+			all = append(all, name)
+		}
+	}
+
+	return func(h *HTTPClient) {
+		h.dedup = &flightGroup{
+			headers: all,
+			calls:   map[string]*flightCall{},
+		}
+	}
+}
+
+type flightGroup struct {
+	headers []string
+	mutex   sync.Mutex
+	calls   map[string]*flightCall
+}
+
+// flightCall is a request in flight
+type flightCall struct {
+	done chan struct{}
+	ctx  context.Context
+	dups int
+	resp *http.Response
+	body []byte
+	err  error
+}
+
+func (f *flightGroup) wrap(w *WeDeploy, next roundTrip) roundTrip {
+	return func(req *http.Request) (*http.Response, error) {
+		var limit = w.getMaxResponseSize()
+		var key, ok = f.key(w, req, limit)
+
+		if !ok {
+			return next(req)
+		}
+
+		f.mutex.Lock()
+
+		if c, ok := f.calls[key]; ok {
+			c.dups++
+			f.mutex.Unlock()
+			return f.wait(c, req, next)
+		}
+
+		var c = &flightCall{done: make(chan struct{}), ctx: req.Context()}
+		f.calls[key] = c
+		f.mutex.Unlock()
+
+		c.resp, c.body, c.err = readResponse(req, next, limit)
+
+		f.mutex.Lock()
+		delete(f.calls, key)
+		f.mutex.Unlock()
+		close(c.done)
+
+		return c.copy(req)
+	}
+}
+
+// wait for the request in flight, sending the request again if the
+// request in flight was canceled
+func (f *flightGroup) wait(c *flightCall, req *http.Request, next roundTrip) (*http.Response, error) {
+	select {
+	case <-c.done:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+
+	if c.err != nil && c.ctx.Err() != nil && req.Context().Err() == nil {
+		return next(req)
+	}
+
+	return c.copy(req)
+}
+
+// key of the request, if it can be coalesced
+func (f *flightGroup) key(w *WeDeploy, req *http.Request, maxResponseSize int64) (string, bool) {
+	if req.Method != "GET" && req.Method != "HEAD" {
+		return "", false
+	}
+
+	var hash = sha256.New()
+	io.WriteString(hash, req.Method+" "+req.URL.String()+"\n"+
+		strconv.FormatInt(maxResponseSize, 10))
+
+	for _, name := range f.headers {
+		io.WriteString(hash, "\n"+name+": "+strings.Join(req.Header[name], ", "))
+	}
+
+	io.WriteString(hash, "\n\n")
+
+	if !writeBodyKey(hash, w, req) {
+		return "", false
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), true
+}
+
+// readResponse sends the request and reads its response body, up to one
+// byte more than the maximum response size, so that it is still detected
+func readResponse(req *http.Request, next roundTrip, maxResponseSize int64) (*http.Response, []byte, error) {
+	var resp, err = next(req)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer resp.Body.Close()
+
+	var r io.Reader = resp.Body
+
+	if maxResponseSize > 0 {
+		r = io.LimitReader(r, maxResponseSize+1)
+	}
+
+	body, err := ioutil.ReadAll(r)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return resp, body, nil
+}
+
+// copy of the response for a caller
+func (c *flightCall) copy(req *http.Request) (*http.Response, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	var resp = *c.resp
+	resp.Header = cloneHeader(c.resp.Header)
+	resp.Body = ioutil.NopCloser(bytes.NewReader(c.body))
+	resp.Request = req
+	return &resp, nil
+}
@@ -0,0 +1,308 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wedeploy
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/henvic/wedeploy-sdk-go/filter"
+)
+
+type blockingServer struct {
+	*httptest.Server
+	hits    int32
+	release chan struct{}
+}
+
+func setupBlockingServer() *blockingServer {
+	var b = &blockingServer{release: make(chan struct{})}
+
+	b.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&b.hits, 1)
+		<-b.release
+		var body, _ = ioutil.ReadAll(r.Body)
+		w.Write([]byte(`{"query":` + string(body) + `}`))
+	}))
+
+	return b
+}
+
+// waitFlight waits until the requests in flight have the given duplicates
+func waitFlight(t *testing.T, f *flightGroup, dups ...int) {
+	for i := 0; i < 500; i++ {
+		f.mutex.Lock()
+		var got = map[int]int{}
+
+		for _, c := range f.calls {
+			got[c.dups]++
+		}
+
+		f.mutex.Unlock()
+
+		var want = map[int]int{}
+
+		for _, d := range dups {
+			want[d]++
+		}
+
+		if len(got) == len(want) {
+			var equal = true
+
+			for k, v := range want {
+				equal = equal && got[k] == v
+			}
+
+			if equal {
+				return
+			}
+		}
+
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	t.Fatalf("Timed out waiting for requests in flight with duplicates %v", dups)
+}
+
+func TestDeduplication(t *testing.T) {
+	var s = setupBlockingServer()
+	defer s.Close()
+
+	var client = NewHTTPClient(WithBaseURL(s.URL), WithDeduplication())
+	var wg sync.WaitGroup
+	var bodies = make([]map[string]interface{}, 10)
+	var errs = make([]error, 10)
+
+	for i := range bodies {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			var req = client.URL("books").Filter(filter.Equal("year", 1603))
+
+			if errs[i] = req.Get(); errs[i] == nil {
+				errs[i] = req.DecodeJSON(&bodies[i])
+			}
+		}(i)
+	}
+
+	waitFlight(t, client.dedup, 9)
+	close(s.release)
+	wg.Wait()
+
+	if atomic.LoadInt32(&s.hits) != 1 {
+		t.Errorf("Expected a single request to be sent, got %v instead", s.hits)
+	}
+
+	for i := range bodies {
+		if errs[i] != nil {
+			t.Errorf("Expected no error, got %v instead", errs[i])
+		}
+
+		if bodies[i]["query"] == nil {
+			t.Errorf("Expected each caller to decode the response, got %v instead", bodies[i])
+		}
+	}
+
+	if len(client.dedup.calls) != 0 {
+		t.Errorf("Expected no requests in flight, got %v instead", client.dedup.calls)
+	}
+}
+
+func TestDeduplicationEquivalentQueries(t *testing.T) {
+	var s = setupBlockingServer()
+	defer s.Close()
+
+	var client = NewHTTPClient(WithBaseURL(s.URL), WithDeduplication())
+	var wg sync.WaitGroup
+
+	for _, req := range []*WeDeploy{
+		client.URL("books").Filter("a", 1).Filter("b", 2),
+		client.URL("books").Filter("b", 2).Filter("a", 1),
+	} {
+		wg.Add(1)
+
+		go func(req *WeDeploy) {
+			defer wg.Done()
+
+			if err := req.Get(); err != nil {
+				t.Error(err)
+			}
+		}(req)
+	}
+
+	waitFlight(t, client.dedup, 1)
+	close(s.release)
+	wg.Wait()
+
+	if atomic.LoadInt32(&s.hits) != 1 {
+		t.Errorf("Expected a single request to be sent, got %v instead", s.hits)
+	}
+}
+
+func TestDeduplicationDistinctRequests(t *testing.T) {
+	var s = setupBlockingServer()
+	defer s.Close()
+
+	var client = NewHTTPClient(WithBaseURL(s.URL), WithDeduplication())
+	var requests = []*WeDeploy{
+		client.URL("books").Filter(filter.Equal("year", 1603)),
+		client.URL("books").Filter(filter.Equal("year", 1623)),
+		client.URL("books").Filter(filter.Equal("year", 1603)).Auth("token"),
+		client.URL("movies").Filter(filter.Equal("year", 1603)),
+		client.URL("books").Filter(filter.Equal("year", 1603)).MaxResponseSize(1 << 20),
+	}
+
+	var wg sync.WaitGroup
+
+	for _, req := range requests {
+		wg.Add(1)
+
+		go func(req *WeDeploy) {
+			defer wg.Done()
+
+			if err := req.Get(); err != nil {
+				t.Error(err)
+			}
+		}(req)
+	}
+
+	waitFlight(t, client.dedup, 0, 0, 0, 0, 0)
+
+	// POST requests are never coalesced
+	wg.Add(2)
+
+	for i := 0; i < 2; i++ {
+		go func() {
+			defer wg.Done()
+
+			if err := client.URL("books").Post(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+
+	for atomic.LoadInt32(&s.hits) != 7 {
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	close(s.release)
+	wg.Wait()
+}
+
+func TestDeduplicationCustomHeaders(t *testing.T) {
+	var s = setupBlockingServer()
+	defer s.Close()
+
+	var client = NewHTTPClient(WithBaseURL(s.URL), WithDeduplication("x-tenant"))
+
+	var want = []string{"Authorization", "Cookie", "Accept", "X-Tenant"}
+
+	if !reflect.DeepEqual(client.dedup.headers, want) {
+		t.Errorf("Expected headers %v, got %v instead", want, client.dedup.headers)
+	}
+
+	var requests = []*WeDeploy{
+		client.URL("books").Header("X-Tenant", "a").Auth("alice"),
+		client.URL("books").Header("X-Tenant", "a").Auth("bob"),
+		client.URL("books").Header("X-Tenant", "a").Header("Cookie", "session=1"),
+		client.URL("books").Header("X-Tenant", "b"),
+	}
+
+	var wg sync.WaitGroup
+
+	for _, req := range requests {
+		wg.Add(1)
+
+		go func(req *WeDeploy) {
+			defer wg.Done()
+
+			if err := req.Get(); err != nil {
+				t.Error(err)
+			}
+		}(req)
+	}
+
+	waitFlight(t, client.dedup, 0, 0, 0, 0)
+	close(s.release)
+	wg.Wait()
+}
+
+func TestDeduplicationSequential(t *testing.T) {
+	var s = setupBlockingServer()
+	defer s.Close()
+	close(s.release)
+
+	var client = NewHTTPClient(WithBaseURL(s.URL), WithDeduplication())
+
+	for i := 0; i < 2; i++ {
+		if err := client.URL("books").Get(); err != nil {
+			t.Error(err)
+		}
+	}
+
+	if atomic.LoadInt32(&s.hits) != 2 {
+		t.Errorf("Expected responses not to be reused after completion, got %v hits", s.hits)
+	}
+}
+
+func TestDeduplicationCanceled(t *testing.T) {
+	var s = setupBlockingServer()
+	defer s.Close()
+
+	var client = NewHTTPClient(WithBaseURL(s.URL), WithDeduplication())
+	var leaderCtx, cancelLeader = context.WithCancel(context.Background())
+	var followerCtx, cancelFollower = context.WithCancel(context.Background())
+
+	var get = func(ctx context.Context) chan error {
+		var c = make(chan error, 1)
+
+		go func() {
+			var req = client.URL("books")
+			req.SetContext(ctx)
+			c <- req.Get()
+		}()
+
+		return c
+	}
+
+	var leader = get(leaderCtx)
+	waitFlight(t, client.dedup, 0)
+
+	var follower = get(followerCtx)
+	var retried = get(context.Background())
+	waitFlight(t, client.dedup, 2)
+
+	cancelFollower()
+
+	if err := <-follower; err != context.Canceled {
+		t.Errorf("Expected follower to be canceled, got %v instead", err)
+	}
+
+	cancelLeader()
+
+	if err := <-leader; err == nil {
+		t.Errorf("Expected leader to be canceled")
+	}
+
+	// the remaining request is sent again by itself
+	for atomic.LoadInt32(&s.hits) != 2 {
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	close(s.release)
+
+	if err := <-retried; err != nil {
+		t.Errorf("Expected request to be sent again after the leader was canceled, got %v", err)
+	}
+}
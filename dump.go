@@ -0,0 +1,193 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wedeploy
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// ErrBodyNotReplayable is returned when dumping a request with a body
+// that can't be read without consuming it
+var ErrBodyNotReplayable = errors.New(
+	"request body must be a *bytes.Buffer, *bytes.Reader or *strings.Reader to be dumped")
+
+// DumpOption for Curl and Dump
+type DumpOption func(*dumpSettings)
+
+type dumpSettings struct {
+	mask bool
+}
+
+// MaskCredentials replaces the Authorization and Cookie header values and
+// the URL password with REDACTED
+func MaskCredentials() DumpOption {
+	return func(s *dumpSettings) {
+		s.mask = true
+	}
+}
+
+// Curl renders the request as a curl command, as it would be sent with the
+// given method, without sending it.
+// Bodies compressed by the client are rendered uncompressed, without the
+// Content-Encoding header.
+func (w *WeDeploy) Curl(method string, opts ...DumpOption) (string, error) {
+	var req, body, err = w.prepareDump(method, opts...)
+
+	if err != nil {
+		return "", err
+	}
+
+	var b bytes.Buffer
+	b.WriteString("curl")
+
+	// curl sends a body with POST, unless the method is set
+	switch {
+	case body == nil && method == "GET":
+	case body == nil && method == "HEAD":
+		b.WriteString(" --head")
+	default:
+		b.WriteString(" -X " + method)
+	}
+
+	b.WriteString(" " + shellQuote(req.URL.String()))
+
+	var keys []string
+
+	for key := range req.Header {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		for _, value := range req.Header[key] {
+			b.WriteString(" -H " + shellQuote(key+": "+value))
+		}
+	}
+
+	if body != nil {
+		b.WriteString(" --data-binary " + shellQuote(string(body)))
+	}
+
+	return b.String(), nil
+}
+
+// Dump renders the request in its HTTP/1.1 wire representation, as it would
+// be sent with the given method, without sending it.
+// Bodies compressed by the client are rendered uncompressed, without the
+// Content-Encoding header.
+func (w *WeDeploy) Dump(method string, opts ...DumpOption) ([]byte, error) {
+	var req, body, err = w.prepareDump(method, opts...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if body != nil {
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	return httputil.DumpRequestOut(req, true)
+}
+
+// prepareDump sets up a copy of the request as setupAction would do for
+// sending it, and reads its body. The idempotency key is set up on the
+// request itself, so that the copy has the key that is sent.
+func (w *WeDeploy) prepareDump(method string, opts ...DumpOption) (*http.Request, []byte, error) {
+	var s = &dumpSettings{}
+
+	for _, o := range opts {
+		o(s)
+	}
+
+	if err := w.setupIdempotencyKey(method); err != nil {
+		return nil, nil, err
+	}
+
+	var c = w.Clone()
+	c.IdempotencyKey = w.IdempotencyKey
+
+	if err := c.setupAction(method); err != nil {
+		c.cancelRemainingTimeout()
+		return nil, nil, err
+	}
+
+	c.cancelRemainingTimeout()
+
+	var req = c.Request
+	req.Header = cloneHeader(req.Header)
+
+	var body []byte
+
+	if c.uncompressedBody != nil {
+		// render the body as it is before compression
+		body = c.uncompressedBody
+		req.Header.Del("Content-Encoding")
+		req.ContentLength = int64(len(body))
+		req.Body = nil
+	} else if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return nil, nil, ErrBodyNotReplayable
+		}
+
+		var rc, err = req.GetBody()
+
+		if err == nil {
+			body, err = ioutil.ReadAll(rc)
+			rc.Close()
+		}
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		req.Body = nil
+	}
+
+	if s.mask {
+		maskCredentials(req)
+	}
+
+	return req, body, nil
+}
+
+func maskCredentials(req *http.Request) {
+	for _, key := range []string{"Authorization", "Proxy-Authorization"} {
+		var values = req.Header[key]
+
+		for i, v := range values {
+			// keep the authentication scheme, such as Basic or Bearer
+			if n := strings.Index(v, " "); n != -1 {
+				values[i] = v[:n] + " REDACTED"
+			} else {
+				values[i] = "REDACTED"
+			}
+		}
+	}
+
+	if _, ok := req.Header["Cookie"]; ok {
+		req.Header.Set("Cookie", "REDACTED")
+	}
+
+	if req.URL.User != nil {
+		if _, ok := req.URL.User.Password(); ok {
+			var u = *req.URL
+			u.User = url.UserPassword(req.URL.User.Username(), "REDACTED")
+			req.URL = &u
+		}
+	}
+}
+
+// shellQuote quotes a string for POSIX shells
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
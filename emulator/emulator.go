@@ -0,0 +1,593 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package emulator is an in-memory WeDeploy Data server,
+// for testing without a live project.
+//
+// It supports creating, reading, updating and deleting documents,
+// and queries with the comparison, any, none, exists, missing and regex
+// filters combined with and / or, sort, offset, limit, and count.
+//
+// Requests with an Idempotency-Key header are handled once: sending the
+// same request with the same key again replays the stored response,
+// with the Idempotent-Replayed: true header, and reusing the key for a
+// different request fails with 422 Unprocessable Entity.
+//
+//	var e = emulator.New()
+//	var server = httptest.NewServer(e)
+//	defer server.Close()
+//	var client = wedeploy.NewHTTPClient(wedeploy.WithBaseURL(server.URL))
+package emulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Document stored on a collection
+type Document map[string]interface{}
+
+// Emulator of WeDeploy Data
+type Emulator struct {
+	mutex       sync.Mutex
+	collections map[string][]Document
+	lastID      int
+	failures    []int
+	requests    int
+	idempotency map[string]storedResponse
+}
+
+// storedResponse of a request with an idempotency key
+type storedResponse struct {
+	request string
+	status  int
+	body    []byte
+}
+
+// New creates an empty emulator
+func New() *Emulator {
+	return &Emulator{
+		collections: map[string][]Document{},
+		idempotency: map[string]storedResponse{},
+	}
+}
+
+// Documents of a collection, in insertion order
+func (e *Emulator) Documents(collection string) []Document {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	var docs []Document
+
+	for _, d := range e.collections[collection] {
+		docs = append(docs, copyDocument(d))
+	}
+
+	return docs
+}
+
+// Requests handled by the emulator, including failed ones
+func (e *Emulator) Requests() int {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return e.requests
+}
+
+// Fail the next requests with the given status codes, in order
+func (e *Emulator) Fail(codes ...int) {
+	e.mutex.Lock()
+	e.failures = append(e.failures, codes...)
+	e.mutex.Unlock()
+}
+
+type httpError struct {
+	code    int
+	message string
+}
+
+func (h httpError) Error() string {
+	return h.message
+}
+
+func errorf(code int, format string, a ...interface{}) error {
+	return httpError{code, fmt.Sprintf(format, a...)}
+}
+
+// ServeHTTP handles Data requests
+func (e *Emulator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body, err = ioutil.ReadAll(r.Body)
+
+	if err != nil {
+		writeError(w, errorf(http.StatusBadRequest, "can't read body: %v", err))
+		return
+	}
+
+	var status, bin, replayed, errr = e.respond(r, body)
+
+	if errr != nil {
+		writeError(w, errr)
+		return
+	}
+
+	if replayed {
+		w.Header().Set("Idempotent-Replayed", "true")
+	}
+
+	writeJSON(w, status, bin)
+}
+
+// respond to a request, replaying the stored response of a request
+// with the same idempotency key
+func (e *Emulator) respond(r *http.Request, body []byte) (int, []byte, bool, error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	var key = r.Header.Get("Idempotency-Key")
+	var request = r.Method + " " + r.URL.Path + "\n" + string(body)
+
+	if stored, ok := e.idempotency[key]; ok && key != "" {
+		e.requests++
+
+		if stored.request != request {
+			return 0, nil, false, errorf(http.StatusUnprocessableEntity,
+				"idempotency key %v used by a different request", key)
+		}
+
+		return stored.status, stored.body, true, nil
+	}
+
+	var failure = len(e.failures) != 0
+	var status, v = e.handle(r, body)
+	var bin, err = json.Marshal(v)
+
+	if err != nil {
+		return 0, nil, false, errorf(http.StatusInternalServerError, "can't encode response: %v", err)
+	}
+
+	// emulated failures and server errors aren't stored, so they can be retried
+	if key != "" && !failure && status < 500 {
+		e.idempotency[key] = storedResponse{request, status, bin}
+	}
+
+	return status, bin, false, nil
+}
+
+func (e *Emulator) handle(r *http.Request, body []byte) (int, interface{}) {
+	e.requests++
+
+	if len(e.failures) != 0 {
+		var code = e.failures[0]
+		e.failures = e.failures[1:]
+		return code, errorBody(code, "emulated failure")
+	}
+
+	var collection, id = splitPath(r.URL.Path)
+	var v interface{}
+	var err error
+
+	if collection == "" {
+		return http.StatusNotFound, errorBody(http.StatusNotFound, "collection not found")
+	}
+
+	var status = http.StatusOK
+
+	switch {
+	case id == "" && r.Method == "GET":
+		v, err = e.query(collection, body)
+	case id == "" && r.Method == "POST":
+		v, err = e.create(collection, body)
+		status = http.StatusCreated
+	case id != "" && r.Method == "GET":
+		v, err = e.get(collection, id)
+	case id != "" && r.Method == "PUT":
+		v, err = e.replace(collection, id, body)
+	case id != "" && r.Method == "PATCH":
+		v, err = e.update(collection, id, body)
+	case id != "" && r.Method == "DELETE":
+		err = e.delete(collection, id)
+		status = http.StatusNoContent
+	case id == "" && r.Method == "DELETE":
+		delete(e.collections, collection)
+		status = http.StatusNoContent
+	default:
+		err = errorf(http.StatusMethodNotAllowed, "method %v not allowed", r.Method)
+	}
+
+	if err != nil {
+		var he, ok = err.(httpError)
+
+		if !ok {
+			he = httpError{http.StatusInternalServerError, err.Error()}
+		}
+
+		return he.code, errorBody(he.code, he.message)
+	}
+
+	return status, v
+}
+
+func splitPath(path string) (collection, id string) {
+	var parts = strings.Split(strings.Trim(path, "/"), "/")
+
+	switch len(parts) {
+	case 1:
+		return parts[0], ""
+	case 2:
+		return parts[0], parts[1]
+	}
+
+	return "", ""
+}
+
+func (e *Emulator) find(collection, id string) int {
+	for n, d := range e.collections[collection] {
+		if fmt.Sprint(d["id"]) == id {
+			return n
+		}
+	}
+
+	return -1
+}
+
+func (e *Emulator) get(collection, id string) (interface{}, error) {
+	var n = e.find(collection, id)
+
+	if n == -1 {
+		return nil, errorf(http.StatusNotFound, "document %v not found", id)
+	}
+
+	return e.collections[collection][n], nil
+}
+
+func (e *Emulator) create(collection string, body []byte) (interface{}, error) {
+	var d Document
+
+	if err := json.Unmarshal(body, &d); err != nil || d == nil {
+		return nil, errorf(http.StatusBadRequest, "invalid document")
+	}
+
+	if _, ok := d["id"]; !ok {
+		e.lastID++
+		d["id"] = strconv.Itoa(e.lastID)
+	}
+
+	if e.find(collection, fmt.Sprint(d["id"])) != -1 {
+		return nil, errorf(http.StatusConflict, "document %v already exists", d["id"])
+	}
+
+	e.collections[collection] = append(e.collections[collection], d)
+	return d, nil
+}
+
+func (e *Emulator) replace(collection, id string, body []byte) (interface{}, error) {
+	var d Document
+
+	if err := json.Unmarshal(body, &d); err != nil || d == nil {
+		return nil, errorf(http.StatusBadRequest, "invalid document")
+	}
+
+	d["id"] = id
+
+	if n := e.find(collection, id); n != -1 {
+		e.collections[collection][n] = d
+	} else {
+		e.collections[collection] = append(e.collections[collection], d)
+	}
+
+	return d, nil
+}
+
+func (e *Emulator) update(collection, id string, body []byte) (interface{}, error) {
+	var fields Document
+
+	if err := json.Unmarshal(body, &fields); err != nil || fields == nil {
+		return nil, errorf(http.StatusBadRequest, "invalid document")
+	}
+
+	var n = e.find(collection, id)
+
+	if n == -1 {
+		return nil, errorf(http.StatusNotFound, "document %v not found", id)
+	}
+
+	var d = e.collections[collection][n]
+
+	for k, v := range fields {
+		if k != "id" {
+			d[k] = v
+		}
+	}
+
+	return d, nil
+}
+
+func (e *Emulator) delete(collection, id string) error {
+	var n = e.find(collection, id)
+
+	if n == -1 {
+		return errorf(http.StatusNotFound, "document %v not found", id)
+	}
+
+	var docs = e.collections[collection]
+	e.collections[collection] = append(docs[:n:n], docs[n+1:]...)
+	return nil
+}
+
+type queryBody struct {
+	Type   string                       `json:"type"`
+	Filter []map[string]json.RawMessage `json:"filter"`
+	Sort   []map[string]string          `json:"sort"`
+	Offset int                          `json:"offset"`
+	Limit  *int                         `json:"limit"`
+}
+
+func (e *Emulator) query(collection string, body []byte) (interface{}, error) {
+	var q queryBody
+
+	if len(body) != 0 {
+		if err := json.Unmarshal(body, &q); err != nil {
+			return nil, errorf(http.StatusBadRequest, "invalid query: %v", err)
+		}
+	}
+
+	if q.Offset < 0 || (q.Limit != nil && *q.Limit < 0) {
+		return nil, errorf(http.StatusBadRequest, "invalid offset or limit")
+	}
+
+	var docs = []Document{}
+
+	for _, d := range e.collections[collection] {
+		var ok, err = matchAll(d, q.Filter)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			docs = append(docs, d)
+		}
+	}
+
+	if q.Type == "count" {
+		return len(docs), nil
+	}
+
+	sortDocuments(docs, q.Sort)
+
+	if q.Offset > len(docs) {
+		q.Offset = len(docs)
+	}
+
+	docs = docs[q.Offset:]
+
+	if q.Limit != nil && *q.Limit < len(docs) {
+		docs = docs[:*q.Limit]
+	}
+
+	return docs, nil
+}
+
+func matchAll(d Document, filters []map[string]json.RawMessage) (bool, error) {
+	for _, f := range filters {
+		var ok, err = match(d, f)
+
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+func match(d Document, f map[string]json.RawMessage) (bool, error) {
+	for field, raw := range f {
+		var ok, err = matchField(d, field, raw)
+
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+func matchField(d Document, field string, raw json.RawMessage) (bool, error) {
+	if field == "and" || field == "or" {
+		var filters []map[string]json.RawMessage
+
+		if err := json.Unmarshal(raw, &filters); err != nil {
+			return false, errorf(http.StatusBadRequest, "invalid %v filter", field)
+		}
+
+		for _, f := range filters {
+			var ok, err = match(d, f)
+
+			if err != nil {
+				return false, err
+			}
+
+			if ok == (field == "or") {
+				return ok, nil
+			}
+		}
+
+		return field == "and", nil
+	}
+
+	var c struct {
+		Operator string      `json:"operator"`
+		Value    interface{} `json:"value"`
+	}
+
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return false, errorf(http.StatusBadRequest, "invalid filter on %v", field)
+	}
+
+	var v, exists = d[field]
+
+	switch c.Operator {
+	case "=":
+		return reflect.DeepEqual(v, c.Value), nil
+	case "!=":
+		return !reflect.DeepEqual(v, c.Value), nil
+	case ">", ">=", "<", "<=", "=<":
+		return compareOperator(c.Operator, compare(v, c.Value)), nil
+	case "any", "none":
+		var values, _ = c.Value.([]interface{})
+		var found = false
+
+		for _, value := range values {
+			found = found || reflect.DeepEqual(v, value)
+		}
+
+		return found == (c.Operator == "any"), nil
+	case "exists":
+		return exists, nil
+	case "missing":
+		return !exists, nil
+	case "~":
+		var re, err = regexp.Compile(fmt.Sprint(c.Value))
+
+		if err != nil {
+			return false, errorf(http.StatusBadRequest, "invalid regex: %v", err)
+		}
+
+		var s, ok = v.(string)
+		return ok && re.MatchString(s), nil
+	}
+
+	return false, errorf(http.StatusBadRequest, "unsupported operator %q", c.Operator)
+}
+
+func compareOperator(operator string, c int) bool {
+	switch operator {
+	case ">":
+		return c == 1
+	case ">=":
+		return c == 1 || c == 0
+	case "<":
+		return c == -1
+	}
+
+	return c == -1 || c == 0
+}
+
+// compare values of the same type, returning 2 if they aren't comparable
+func compare(a, b interface{}) int {
+	switch x := a.(type) {
+	case float64:
+		if y, ok := b.(float64); ok {
+			return compareFloat(x, y)
+		}
+	case string:
+		if y, ok := b.(string); ok {
+			return strings.Compare(x, y)
+		}
+	case bool:
+		if y, ok := b.(bool); ok && x != y {
+			if y {
+				return -1
+			}
+
+			return 1
+		} else if ok {
+			return 0
+		}
+	}
+
+	return 2
+}
+
+func compareFloat(x, y float64) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+
+	return 0
+}
+
+func sortDocuments(docs []Document, sorts []map[string]string) {
+	sort.SliceStable(docs, func(i, j int) bool {
+		for _, s := range sorts {
+			for field, direction := range s {
+				var c = compareSort(docs[i][field], docs[j][field])
+
+				if c == 0 {
+					continue
+				}
+
+				if direction == "desc" {
+					return c == 1
+				}
+
+				return c == -1
+			}
+		}
+
+		return false
+	})
+}
+
+// compareSort compares values, putting missing values last
+func compareSort(a, b interface{}) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+
+	if c := compare(a, b); c != 2 {
+		return c
+	}
+
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func copyDocument(d Document) Document {
+	var c = Document{}
+
+	for k, v := range d {
+		c[k] = v
+	}
+
+	return c
+}
+
+func errorBody(code int, message string) interface{} {
+	return map[string]interface{}{
+		"code":    code,
+		"message": message,
+	}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	var he = err.(httpError)
+	var bin, _ = json.Marshal(errorBody(he.code, he.message))
+	writeJSON(w, he.code, bin)
+}
+
+func writeJSON(w http.ResponseWriter, status int, bin []byte) {
+	if status == http.StatusNoContent {
+		w.WriteHeader(status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_, _ = w.Write(bin)
+}
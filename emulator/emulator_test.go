@@ -0,0 +1,274 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package emulator
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/henvic/wedeploy-sdk-go"
+	"github.com/henvic/wedeploy-sdk-go/filter"
+)
+
+func setupEmulator(t *testing.T) (*Emulator, *httptest.Server, *wedeploy.HTTPClient) {
+	var e = New()
+	var s = httptest.NewServer(e)
+	return e, s, wedeploy.NewHTTPClient(wedeploy.WithBaseURL(s.URL))
+}
+
+func create(t *testing.T, hc *wedeploy.HTTPClient, docs ...string) {
+	for _, d := range docs {
+		if err := hc.URL("books").Body(jsonBody(d)).Post(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func titles(t *testing.T, req *wedeploy.WeDeploy) []string {
+	if err := req.Get(); err != nil {
+		t.Fatal(err)
+	}
+
+	var docs []map[string]interface{}
+
+	if err := req.DecodeJSON(&docs); err != nil {
+		t.Fatal(err)
+	}
+
+	var list = []string{}
+
+	for _, d := range docs {
+		list = append(list, d["title"].(string))
+	}
+
+	return list
+}
+
+func TestCRUD(t *testing.T) {
+	var e, s, hc = setupEmulator(t)
+	defer s.Close()
+
+	var req = hc.URL("books").Body(jsonBody(`{"title":"Hamlet"}`))
+
+	if err := req.Post(); err != nil {
+		t.Fatal(err)
+	}
+
+	var created map[string]interface{}
+
+	if err := req.DecodeJSON(&created); err != nil || created["id"] != "1" {
+		t.Errorf("Expected document with ID 1, got %v (%v) instead", created, err)
+	}
+
+	if err := hc.URL("books").Body(jsonBody(`{"id":"1"}`)).Post(); err != (wedeploy.StatusError{Code: http.StatusConflict}) {
+		t.Errorf("Expected conflict, got %v instead", err)
+	}
+
+	if err := hc.URL("books", "1").Body(jsonBody(`{"year":1603}`)).Patch(); err != nil {
+		t.Error(err)
+	}
+
+	if err := hc.URL("books", "2").Body(jsonBody(`{"title":"Macbeth"}`)).Put(); err != nil {
+		t.Error(err)
+	}
+
+	var got = hc.URL("books", "1")
+
+	if err := got.Get(); err != nil {
+		t.Fatal(err)
+	}
+
+	var doc map[string]interface{}
+
+	if err := got.DecodeJSON(&doc); err != nil {
+		t.Error(err)
+	}
+
+	var want = map[string]interface{}{"id": "1", "title": "Hamlet", "year": 1603.0}
+
+	if !reflect.DeepEqual(doc, want) {
+		t.Errorf("Expected document %v, got %v instead", want, doc)
+	}
+
+	if err := hc.URL("books", "1").Delete(); err != nil {
+		t.Error(err)
+	}
+
+	if err := hc.URL("books", "1").Get(); err != (wedeploy.StatusError{Code: http.StatusNotFound}) {
+		t.Errorf("Expected not found, got %v instead", err)
+	}
+
+	var docs = e.Documents("books")
+
+	if len(docs) != 1 || docs[0]["title"] != "Macbeth" || docs[0]["id"] != "2" {
+		t.Errorf("Unexpected documents %v", docs)
+	}
+
+	if err := hc.URL("books").Delete(); err != nil || len(e.Documents("books")) != 0 {
+		t.Errorf("Expected collection to be deleted (%v)", err)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	var _, s, hc = setupEmulator(t)
+	defer s.Close()
+
+	create(t, hc,
+		`{"title":"Hamlet","year":1603,"genre":"tragedy"}`,
+		`{"title":"Macbeth","year":1623,"genre":"tragedy"}`,
+		`{"title":"As You Like It","year":1623,"genre":"comedy"}`,
+		`{"title":"Sonnets","year":1609}`)
+
+	var cases = []struct {
+		req  *wedeploy.WeDeploy
+		want []string
+	}{
+		{
+			hc.URL("books").Filter("genre", "tragedy"),
+			[]string{"Hamlet", "Macbeth"},
+		},
+		{
+			hc.URL("books").Filter(filter.Gt("year", 1603)).Sort("title", "desc"),
+			[]string{"Sonnets", "Macbeth", "As You Like It"},
+		},
+		{
+			hc.URL("books").Filter(filter.Lte("year", 1609)).Sort("year"),
+			[]string{"Hamlet", "Sonnets"},
+		},
+		{
+			hc.URL("books").Filter(filter.Or(filter.Equal("genre", "comedy"), filter.Missing("genre"))),
+			[]string{"As You Like It", "Sonnets"},
+		},
+		{
+			hc.URL("books").Filter(filter.Any("year", 1603, 1609)).Filter(filter.Regex("title", "^S")),
+			[]string{"Sonnets"},
+		},
+		{
+			hc.URL("books").Sort("year", "desc").Sort("title").Offset(1).Limit(2),
+			[]string{"Macbeth", "Sonnets"},
+		},
+	}
+
+	for n, c := range cases {
+		if got := titles(t, c.req); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("Expected case %d to be %v, got %v instead", n, c.want, got)
+		}
+	}
+
+	var count = hc.URL("books").Filter("year", 1623).Count()
+
+	if err := count.Get(); err != nil {
+		t.Fatal(err)
+	}
+
+	var n int
+
+	if err := count.DecodeJSON(&n); err != nil || n != 2 {
+		t.Errorf("Expected count 2, got %v (%v) instead", n, err)
+	}
+
+	if err := hc.URL("books").Filter(filter.New("title", "??", 1)).Get(); err != (wedeploy.StatusError{Code: http.StatusBadRequest}) {
+		t.Errorf("Expected bad request for unsupported operator, got %v instead", err)
+	}
+
+	for _, req := range []*wedeploy.WeDeploy{hc.URL("books").Limit(-1), hc.URL("books").Offset(-1)} {
+		if err := req.Get(); err != (wedeploy.StatusError{Code: http.StatusBadRequest}) {
+			t.Errorf("Expected bad request for negative limit or offset, got %v instead", err)
+		}
+	}
+
+	if len(titles(t, hc.URL("books").Offset(10))) != 0 {
+		t.Errorf("Expected no documents after the last one")
+	}
+}
+
+func TestFail(t *testing.T) {
+	var e, s, hc = setupEmulator(t)
+	defer s.Close()
+
+	e.Fail(http.StatusServiceUnavailable, http.StatusTooManyRequests)
+
+	for _, code := range []int{http.StatusServiceUnavailable, http.StatusTooManyRequests} {
+		if err := hc.URL("books").Get(); err != (wedeploy.StatusError{Code: code}) {
+			t.Errorf("Expected status %d, got %v instead", code, err)
+		}
+	}
+
+	if err := hc.URL("books").Get(); err != nil {
+		t.Error(err)
+	}
+
+	if err := hc.URL("/").Get(); err != (wedeploy.StatusError{Code: http.StatusNotFound}) {
+		t.Errorf("Expected not found, got %v instead", err)
+	}
+
+	if e.Requests() != 4 {
+		t.Errorf("Expected 4 requests, got %d instead", e.Requests())
+	}
+}
+
+func TestIdempotencyKey(t *testing.T) {
+	var e, s, _ = setupEmulator(t)
+	defer s.Close()
+
+	var send = func(key, body string) *http.Response {
+		var req, _ = http.NewRequest("POST", s.URL+"/books", strings.NewReader(body))
+		req.Header.Set("Idempotency-Key", key)
+
+		var resp, err = http.DefaultClient.Do(req)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp.Body.Close()
+		return resp
+	}
+
+	var first = send("k1", `{"title":"Hamlet"}`)
+	var replay = send("k1", `{"title":"Hamlet"}`)
+
+	if first.StatusCode != http.StatusCreated || replay.StatusCode != http.StatusCreated {
+		t.Errorf("Expected created, got %v and %v instead", first.StatusCode, replay.StatusCode)
+	}
+
+	if first.Header.Get("Idempotent-Replayed") != "" || replay.Header.Get("Idempotent-Replayed") != "true" {
+		t.Errorf("Expected only the second response to be replayed")
+	}
+
+	if docs := e.Documents("books"); len(docs) != 1 {
+		t.Errorf("Expected a single document, got %v instead", docs)
+	}
+
+	if resp := send("k1", `{"title":"Macbeth"}`); resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("Expected key reuse to fail, got %v instead", resp.StatusCode)
+	}
+
+	e.Fail(http.StatusServiceUnavailable)
+
+	if resp := send("k2", `{"title":"Macbeth"}`); resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected emulated failure, got %v instead", resp.StatusCode)
+	}
+
+	if resp := send("k2", `{"title":"Macbeth"}`); resp.StatusCode != http.StatusCreated {
+		t.Errorf("Expected retry after failure to be handled, got %v instead", resp.StatusCode)
+	}
+
+	if docs := e.Documents("books"); len(docs) != 2 {
+		t.Errorf("Expected 2 documents, got %v instead", docs)
+	}
+
+	if e.Requests() != 5 {
+		t.Errorf("Expected 5 requests, got %d instead", e.Requests())
+	}
+}
+
+func jsonBody(s string) *bytes.Buffer {
+	return bytes.NewBufferString(s)
+}
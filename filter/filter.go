@@ -5,6 +5,8 @@
 package filter
 
 import (
+	"errors"
+
 	"github.com/henvic/wedeploy-sdk-go/geo"
 	"github.com/henvic/wedeploy-sdk-go/qrange"
 )
@@ -12,6 +14,14 @@ import (
 // Filter is a map with the filter data
 type Filter map[string]interface{}
 
+type invalid struct {
+	err error
+}
+
+func (i invalid) MarshalJSON() ([]byte, error) {
+	return nil, i.err
+}
+
 type data struct {
 	Operator string      `json:"operator"`
 	Value    interface{} `json:"value,omitempty"`
@@ -106,6 +116,18 @@ func (f *Filter) Add(args ...interface{}) *Filter {
 	return &m
 }
 
+// Clone creates a deep copy of the filter
+// Filters, maps and slices are copied, other values are shared
+func (f *Filter) Clone() *Filter {
+	var m = make(Filter)
+
+	for k, v := range *f {
+		m[k] = clone(v)
+	}
+
+	return &m
+}
+
 // And creates a new And filter
 func And(filter ...*Filter) *Filter {
 	return Add("and", filter...)
@@ -164,6 +186,10 @@ func Similar(
 }
 
 // Distance creates a new Distance filter
+// The maximum distance is given by a geo.Circle, a qrange.Range,
+// an int (in meters), a geo.Length, or a string such as "5km".
+// Lengths with invalid units fail when the filter is marshaled,
+// so the request is rejected before it is sent.
 func Distance(field string, location interface{}, lr interface{}) *Filter {
 	value := make(map[string]interface{})
 
@@ -171,7 +197,7 @@ func Distance(field string, location interface{}, lr interface{}) *Filter {
 	case geo.Circle:
 		geoCircles := location.(geo.Circle)
 		value["location"] = geoCircles.Coordinates
-		value["max"] = geoCircles.Radius
+		value["max"] = length(geoCircles.Radius)
 	default:
 		value["location"] = location.(geo.Point)
 
@@ -188,6 +214,10 @@ func Distance(field string, location interface{}, lr interface{}) *Filter {
 			}
 		case int:
 			value["max"] = lr.(int)
+		case geo.Length:
+			value["max"] = lr.(geo.Length)
+		case string:
+			value["max"] = length(lr.(string))
 		}
 
 	}
@@ -205,18 +235,48 @@ func Range(field string, args ...interface{}) *Filter {
 }
 
 // Shape creates a new Shape filter
+// geo.Feature and geo.FeatureCollection values contribute their geometries,
+// so decoded GeoJSON can be used directly
 func Shape(field string, shapes ...interface{}) *Filter {
 	value := make(map[string]interface{})
 	value["type"] = "geometrycollection"
-	value["geometries"] = shapes
+	value["geometries"] = geometries(shapes)
 	return New(field, "gs", value)
 }
 
+// ValidShape creates a new Shape filter after validating the geometries
+func ValidShape(field string, shapes ...interface{}) (*Filter, error) {
+	for _, shape := range expand(shapes) {
+		if g, ok := shape.(geo.Geometry); ok {
+			if err := g.Validate(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return Shape(field, shapes...), nil
+}
+
 // Polygon creates a new Polygon filter
 func Polygon(field string, points ...geo.Point) *Filter {
 	return New(field, "gp", points)
 }
 
+// ValidPolygon creates a new Polygon filter after validating the points
+func ValidPolygon(field string, points ...geo.Point) (*Filter, error) {
+	if len(points) < 3 {
+		return nil, errors.New("filter: polygon must have at least 3 points")
+	}
+
+	for _, p := range points {
+		if err := p.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return Polygon(field, points...), nil
+}
+
 // BoundingBox creates a new BoundingBox filter
 func BoundingBox(
 	field string, boxOrUpperLeft interface{}, lowerRight ...interface{}) *Filter {
@@ -234,6 +294,100 @@ func BoundingBox(
 	return Polygon(field, coords...)
 }
 
+func clone(v interface{}) interface{} {
+	switch t := v.(type) {
+	case *data:
+		return &data{
+			Operator: t.Operator,
+			Value:    clone(t.Value),
+		}
+	case *Filter:
+		return t.Clone()
+	case Filter:
+		return *t.Clone()
+	case []*Filter:
+		var filters = make([]*Filter, len(t))
+
+		for i, f := range t {
+			filters[i] = f.Clone()
+		}
+
+		return filters
+	case []Filter:
+		var filters = make([]Filter, len(t))
+
+		for i, f := range t {
+			filters[i] = *f.Clone()
+		}
+
+		return filters
+	case map[string]interface{}:
+		var m = make(map[string]interface{}, len(t))
+
+		for k, mv := range t {
+			m[k] = clone(mv)
+		}
+
+		return m
+	case []interface{}:
+		var list = make([]interface{}, len(t))
+
+		for i, lv := range t {
+			list[i] = clone(lv)
+		}
+
+		return list
+	}
+
+	return v
+}
+
+func length(s string) interface{} {
+	var l, err = geo.ParseLength(s)
+
+	if err != nil {
+		return invalid{err}
+	}
+
+	return l
+}
+
+func expand(shapes []interface{}) []interface{} {
+	var list = []interface{}{}
+
+	for _, shape := range shapes {
+		switch shape.(type) {
+		case geo.Feature:
+			if g := shape.(geo.Feature).Geometry; g != nil {
+				list = append(list, g)
+			}
+		case geo.FeatureCollection:
+			for _, g := range shape.(geo.FeatureCollection).Geometries() {
+				list = append(list, g)
+			}
+		default:
+			list = append(list, shape)
+		}
+	}
+
+	return list
+}
+
+func geometries(shapes []interface{}) []interface{} {
+	var list = expand(shapes)
+
+	for i, shape := range list {
+		if _, ok := shape.(geo.Point); ok {
+			list[i] = map[string]interface{}{
+				"type":        "point",
+				"coordinates": shape,
+			}
+		}
+	}
+
+	return list
+}
+
 func q(
 	qType, fieldOrQuery string, query interface{}, fuzziness interface{}) *Filter {
 	var field string
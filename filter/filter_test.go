@@ -5,6 +5,7 @@
 package filter
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/henvic/wedeploy-sdk-go/geo"
@@ -52,6 +53,47 @@ func TestBoundingBoxByGeoPoints(t *testing.T) {
 	jsonlib.AssertJSONMarshal(t, want, got)
 }
 
+func TestBoundingBoxGeohash(t *testing.T) {
+	var want = `{
+    "cell": {
+        "operator": "gp",
+        "value": [
+            [45, -90],
+            [0, -45]
+        ]
+    }
+}`
+	var box, err = geo.GeohashBounds("d")
+
+	if err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	var got = BoundingBox("cell", box)
+	jsonlib.AssertJSONMarshal(t, want, got)
+}
+
+func TestClone(t *testing.T) {
+	var want = `{
+    "or": [
+        {"age": {"operator": ">", "value": 12}},
+        {"point": {"operator": "gd", "value": {"location": [0, 0], "max": 2}}}
+    ]
+}`
+
+	var original = Or(Gt("age", 12), Distance("point", geo.NewPoint(0, 0), 2))
+	var got = original.Clone()
+
+	jsonlib.AssertJSONMarshal(t, want, got)
+
+	var filters = (*got)["or"].([]*Filter)
+	(*filters[0])["age"].(*data).Value = 15
+	(*filters[1])["point"].(*data).Value.(map[string]interface{})["max"] = 3
+	(*got)["and"] = filters
+
+	jsonlib.AssertJSONMarshal(t, want, original)
+}
+
 func TestComposingAdd(t *testing.T) {
 	var want = `{
   "and": [
@@ -233,6 +275,48 @@ func TestDistancePointRange(t *testing.T) {
 	jsonlib.AssertJSONMarshal(t, want, got)
 }
 
+func TestDistancePointLength(t *testing.T) {
+	var want = `{
+    "point": {
+        "operator": "gd",
+        "value": {
+            "location": [0, 0],
+            "max": "3mi"
+        }
+    }
+}`
+	var got = Distance("point", geo.NewPoint(0, 0), geo.NewLength(3, geo.Miles))
+	jsonlib.AssertJSONMarshal(t, want, got)
+}
+
+func TestDistancePointLengthString(t *testing.T) {
+	var want = `{
+    "point": {
+        "operator": "gd",
+        "value": {
+            "location": [0, 0],
+            "max": "500m"
+        }
+    }
+}`
+	var got = Distance("point", geo.NewPoint(0, 0), "500 meters")
+	jsonlib.AssertJSONMarshal(t, want, got)
+}
+
+func TestDistanceInvalidUnit(t *testing.T) {
+	var cases = []*Filter{
+		Distance("point", geo.NewPoint(0, 0), "5 furlongs"),
+		Distance("point", geo.NewPoint(0, 0), geo.NewLength(5, "furlongs")),
+		Distance("point", geo.NewCircle(geo.NewPoint(0, 0), "5 furlongs"), nil),
+	}
+
+	for _, c := range cases {
+		if _, err := json.Marshal(c); err == nil {
+			t.Errorf("Expected error marshaling distance with invalid unit")
+		}
+	}
+}
+
 func TestExists(t *testing.T) {
 	var want = `{
     "age": {
@@ -457,6 +541,39 @@ func TestPolygon(t *testing.T) {
 	jsonlib.AssertJSONMarshal(t, want, got)
 }
 
+func TestValidPolygon(t *testing.T) {
+	var got, err = ValidPolygon("xshape",
+		geo.NewPoint(10, 0),
+		geo.NewPoint(20, 0),
+		geo.NewPoint(15, 10))
+
+	if err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	jsonlib.AssertJSONMarshal(t, `{
+    "xshape": {
+        "operator": "gp",
+        "value": [[10, 0], [20, 0], [15, 10]]
+    }
+}`, got)
+}
+
+func TestValidPolygonErrors(t *testing.T) {
+	if _, err := ValidPolygon("xshape",
+		geo.NewPoint(10, 0),
+		geo.NewPoint(20, 0)); err == nil {
+		t.Errorf("Expected error for polygon with 2 points")
+	}
+
+	if _, err := ValidPolygon("xshape",
+		geo.NewPoint(10, 0),
+		geo.NewPoint(100, 0),
+		geo.NewPoint(15, 10)); err != geo.ErrSwappedCoordinates {
+		t.Errorf("Expected error %v, got %v instead", geo.ErrSwappedCoordinates, err)
+	}
+}
+
 func TestPrefix(t *testing.T) {
 	var want = `{
     "*": {
@@ -525,6 +642,63 @@ func TestShapes(t *testing.T) {
 	jsonlib.AssertJSONMarshal(t, want, got)
 }
 
+func TestValidShape(t *testing.T) {
+	var got, err = ValidShape("xshape",
+		geo.NewCircle(geo.NewPoint(0, 0), "2km"),
+		geo.NewFeature(geo.NewPoint(5, 5), nil))
+
+	if err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	jsonlib.AssertJSONMarshal(t, `{
+    "xshape": {
+        "operator": "gs",
+        "value": {
+            "type": "geometrycollection",
+            "geometries": [
+                {"type": "circle", "coordinates": [0, 0], "radius": "2km"},
+                {"type": "point", "coordinates": [5, 5]}
+            ]
+        }
+    }
+}`, got)
+}
+
+func TestValidShapeClockwise(t *testing.T) {
+	var polygon = geo.NewPolygon(
+		geo.NewPoint(0, 0),
+		geo.NewPoint(10, 0),
+		geo.NewPoint(10, 10),
+		geo.NewPoint(0, 10),
+		geo.NewPoint(0, 0))
+
+	if err := polygon.ValidateWinding(); err != geo.ErrWindingOrder {
+		t.Fatalf("Expected clockwise polygon, got %v instead", err)
+	}
+
+	if _, err := ValidShape("xshape", polygon); err != nil {
+		t.Errorf("Expected clockwise polygon to be valid, got %v instead", err)
+	}
+}
+
+func TestValidShapeErrors(t *testing.T) {
+	var polygon = geo.NewPolygon(
+		geo.NewPoint(0, 0),
+		geo.NewPoint(0, 30),
+		geo.NewPoint(40, 0))
+
+	if _, err := ValidShape("xshape", polygon); err != geo.ErrRingTooShort {
+		t.Errorf("Expected error %v, got %v instead", geo.ErrRingTooShort, err)
+	}
+
+	var fc = geo.NewFeatureCollection(geo.NewFeature(geo.NewPoint(0, 190), nil))
+
+	if _, err := ValidShape("xshape", fc); err != geo.ErrLongitudeOutOfRange {
+		t.Errorf("Expected error %v, got %v instead", geo.ErrLongitudeOutOfRange, err)
+	}
+}
+
 func TestSimilarQuery(t *testing.T) {
 	var want = `{
     "*": {
@@ -564,3 +738,65 @@ func TestSimilarFieldAndFuziness(t *testing.T) {
 	var got = Similar("foo", nil, 0.8)
 	jsonlib.AssertJSONMarshal(t, want, got)
 }
+
+func TestShapesWKT(t *testing.T) {
+	var want = `{
+    "xshape": {
+        "operator": "gs",
+        "value": {
+            "type": "geometrycollection",
+            "geometries": [
+                {
+                    "type": "linestring",
+                    "coordinates": [[1, 2], [3, 4]]
+                }
+            ]
+        }
+    }
+}`
+
+	var line, err = geo.ParseWKT("LINESTRING (2 1, 4 3)")
+
+	if err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	var got = Shape("xshape", line)
+	jsonlib.AssertJSONMarshal(t, want, got)
+}
+
+func TestShapesGeoJSON(t *testing.T) {
+	var want = `{
+    "xshape": {
+        "operator": "gs",
+        "value": {
+            "type": "geometrycollection",
+            "geometries": [
+                {
+                    "type": "point",
+                    "coordinates": [10, 20]
+                },
+                {
+                    "type": "linestring",
+                    "coordinates": [
+                        [0, 0],
+                        [1, 1]
+                    ]
+                },
+                {
+                    "type": "point",
+                    "coordinates": [5, 5]
+                }
+            ]
+        }
+    }
+}`
+
+	var fc = geo.NewFeatureCollection(
+		geo.NewFeature(geo.NewPoint(10, 20), nil),
+		geo.NewFeature(nil, nil),
+		geo.NewFeature(geo.NewLine(geo.NewPoint(0, 0), geo.NewPoint(1, 1)), nil))
+
+	var got = Shape("xshape", fc, geo.NewFeature(geo.NewPoint(5, 5), nil))
+	jsonlib.AssertJSONMarshal(t, want, got)
+}
@@ -4,6 +4,14 @@
 
 package geo
 
+import "encoding/json"
+
+// Geometry is implemented by the geometric types of this package
+type Geometry interface {
+	Validate() error
+	geometry()
+}
+
 // BoundingBox geometric type
 type BoundingBox struct {
 	Type        string  `json:"type"`
@@ -17,12 +25,36 @@ type Circle struct {
 	Radius      string `json:"radius"`
 }
 
+// GeometryCollection geometric type
+type GeometryCollection struct {
+	Type       string     `json:"type"`
+	Geometries []Geometry `json:"geometries"`
+}
+
 // Line geometric type
 type Line struct {
 	Type        string  `json:"type"`
 	Coordinates []Point `json:"coordinates"`
 }
 
+// MultiLine geometric type
+type MultiLine struct {
+	Type        string    `json:"type"`
+	Coordinates [][]Point `json:"coordinates"`
+}
+
+// MultiPoint geometric type
+type MultiPoint struct {
+	Type        string  `json:"type"`
+	Coordinates []Point `json:"coordinates"`
+}
+
+// MultiPolygon geometric type
+type MultiPolygon struct {
+	Type        string      `json:"type"`
+	Coordinates [][][]Point `json:"coordinates"`
+}
+
 // Point geometric type
 type Point [2]float64
 
@@ -42,6 +74,16 @@ func NewPoint(lat, lon float64) Point {
 	return p
 }
 
+// Lat is the latitude of the point
+func (p Point) Lat() float64 {
+	return p[0]
+}
+
+// Lon is the longitude of the point
+func (p Point) Lon() float64 {
+	return p[1]
+}
+
 // NewBoundingBox creates a new bounding box
 func NewBoundingBox(upperLeft, lowerRight Point) BoundingBox {
 	return BoundingBox{
@@ -59,6 +101,34 @@ func NewCircle(coordinates Point, radius string) Circle {
 	}
 }
 
+// NewCircleLength creates a new circle with the radius length
+func NewCircleLength(coordinates Point, radius Length) Circle {
+	return NewCircle(coordinates, radius.String())
+}
+
+// RadiusLength parses the radius of the circle
+func (c Circle) RadiusLength() (Length, error) {
+	return ParseLength(c.Radius)
+}
+
+// MarshalJSON encodes the circle, rejecting radius with invalid length
+func (c Circle) MarshalJSON() ([]byte, error) {
+	if _, err := c.RadiusLength(); err != nil {
+		return nil, err
+	}
+
+	type circle Circle
+	return json.Marshal(circle(c))
+}
+
+// NewGeometryCollection creates a new collection of geometries
+func NewGeometryCollection(geometries ...Geometry) GeometryCollection {
+	return GeometryCollection{
+		Type:       "geometrycollection",
+		Geometries: geometries,
+	}
+}
+
 // NewLine adds a new line formed by the given points
 func NewLine(coordinates ...Point) Line {
 	return Line{
@@ -67,6 +137,42 @@ func NewLine(coordinates ...Point) Line {
 	}
 }
 
+// NewMultiLine creates a new multi line with the given lines
+func NewMultiLine(lines ...Line) MultiLine {
+	var x = [][]Point{}
+
+	for _, l := range lines {
+		x = append(x, l.Coordinates)
+	}
+
+	return MultiLine{
+		Type:        "multilinestring",
+		Coordinates: x,
+	}
+}
+
+// NewMultiPoint creates a new multi point with the given points
+func NewMultiPoint(coordinates ...Point) MultiPoint {
+	return MultiPoint{
+		Type:        "multipoint",
+		Coordinates: coordinates,
+	}
+}
+
+// NewMultiPolygon creates a new multi polygon with the given polygons
+func NewMultiPolygon(polygons ...Polygon) MultiPolygon {
+	var x = [][][]Point{}
+
+	for _, p := range polygons {
+		x = append(x, p.Coordinates)
+	}
+
+	return MultiPolygon{
+		Type:        "multipolygon",
+		Coordinates: x,
+	}
+}
+
 // NewPolygon creates a new polygon
 func NewPolygon(coordinates ...Point) Polygon {
 	var x = [][]Point{coordinates}
@@ -77,7 +183,39 @@ func NewPolygon(coordinates ...Point) Polygon {
 	}
 }
 
+// MarshalJSON encodes the collection, writing points as point shapes
+func (gc GeometryCollection) MarshalJSON() ([]byte, error) {
+	var geometries = []interface{}{}
+
+	for _, g := range gc.Geometries {
+		switch g.(type) {
+		case Point:
+			geometries = append(geometries, map[string]interface{}{
+				"type":        "point",
+				"coordinates": g,
+			})
+		default:
+			geometries = append(geometries, g)
+		}
+	}
+
+	return json.Marshal(map[string]interface{}{
+		"type":       gc.Type,
+		"geometries": geometries,
+	})
+}
+
 // AddHole adds a hole to the region of the polygon
 func (p *Polygon) AddHole(coordinates ...Point) {
 	p.Coordinates = append(p.Coordinates, coordinates)
 }
+
+func (BoundingBox) geometry()        {}
+func (Circle) geometry()             {}
+func (GeometryCollection) geometry() {}
+func (Line) geometry()               {}
+func (MultiLine) geometry()          {}
+func (MultiPoint) geometry()         {}
+func (MultiPolygon) geometry()       {}
+func (Point) geometry()              {}
+func (Polygon) geometry()            {}
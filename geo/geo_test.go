@@ -5,6 +5,7 @@
 package geo
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/henvic/wedeploy-sdk-go/jsonlib"
@@ -28,6 +29,28 @@ func TestCircle(t *testing.T) {
 	jsonlib.AssertJSONMarshal(t, want, circle)
 }
 
+func TestCircleLength(t *testing.T) {
+	var want = `{"type":"circle","coordinates":[20,0],"radius":"3mi"}`
+	var circle = NewCircleLength(NewPoint(20, 0), NewLength(3, Miles))
+	jsonlib.AssertJSONMarshal(t, want, circle)
+}
+
+func TestCircleRadiusUnits(t *testing.T) {
+	for _, radius := range []string{"500", "50cm", "300mm", "12in", "3yd", "2nmi", "2NM"} {
+		if _, err := json.Marshal(NewCircle(NewPoint(20, 0), radius)); err != nil {
+			t.Errorf("Expected circle with radius %v to be valid, got %v instead", radius, err)
+		}
+	}
+}
+
+func TestCircleInvalidRadius(t *testing.T) {
+	var circle = NewCircle(NewPoint(20, 0), "3 parsecs")
+
+	if _, err := json.Marshal(circle); err == nil {
+		t.Errorf("Expected error marshaling circle with invalid radius")
+	}
+}
+
 func TestLine(t *testing.T) {
 	var want = `{"type":"linestring","coordinates":[[10,20],[10,30],[10,40]]}`
 
@@ -75,3 +98,61 @@ func TestPolygon(t *testing.T) {
 
 	jsonlib.AssertJSONMarshal(t, want, polygon)
 }
+
+func TestGeometryCollection(t *testing.T) {
+	var want = `{
+    "type": "geometrycollection",
+    "geometries": [
+        {"type": "point", "coordinates": [10, 20]},
+        {"type": "linestring", "coordinates": [[10, 20], [10, 30]]}
+    ]
+}`
+
+	var gc = NewGeometryCollection(
+		NewPoint(10, 20),
+		NewLine(NewPoint(10, 20), NewPoint(10, 30)))
+
+	jsonlib.AssertJSONMarshal(t, want, gc)
+}
+
+func TestMultiLine(t *testing.T) {
+	var want = `{
+    "type": "multilinestring",
+    "coordinates": [
+        [[10, 20], [10, 30]],
+        [[0, 0], [5, 5]]
+    ]
+}`
+
+	var ml = NewMultiLine(
+		NewLine(NewPoint(10, 20), NewPoint(10, 30)),
+		NewLine(NewPoint(0, 0), NewPoint(5, 5)))
+
+	jsonlib.AssertJSONMarshal(t, want, ml)
+}
+
+func TestMultiPoint(t *testing.T) {
+	var want = `{"type":"multipoint","coordinates":[[10,20],[10,30]]}`
+	var mp = NewMultiPoint(NewPoint(10, 20), NewPoint(10, 30))
+	jsonlib.AssertJSONMarshal(t, want, mp)
+}
+
+func TestMultiPolygon(t *testing.T) {
+	var want = `{
+    "type": "multipolygon",
+    "coordinates": [
+        [
+            [[0, 0], [0, 30], [40, 0]]
+        ],
+        [
+            [[50, 50], [50, 60], [60, 50]]
+        ]
+    ]
+}`
+
+	var mp = NewMultiPolygon(
+		NewPolygon(NewPoint(0, 0), NewPoint(0, 30), NewPoint(40, 0)),
+		NewPolygon(NewPoint(50, 50), NewPoint(50, 60), NewPoint(60, 50)))
+
+	jsonlib.AssertJSONMarshal(t, want, mp)
+}
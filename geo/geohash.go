@@ -0,0 +1,112 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geo
+
+import (
+	"fmt"
+	"strings"
+)
+
+const geohashAlphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// MaxGeohashPrecision is the maximum number of characters of a geohash
+const MaxGeohashPrecision = 12
+
+// EncodeGeohash encodes the point as a geohash with the given precision,
+// in characters. The precision is clamped to the [1, 12] interval.
+func EncodeGeohash(p Point, precision int) string {
+	if precision < 1 {
+		precision = 1
+	}
+
+	if precision > MaxGeohashPrecision {
+		precision = MaxGeohashPrecision
+	}
+
+	var lat, lon = [2]float64{-90, 90}, [2]float64{-180, 180}
+	var hash = make([]byte, 0, precision)
+	var even = true
+	var bit, ch = 0, 0
+
+	for len(hash) < precision {
+		var r, v = &lat, p.Lat()
+
+		if even {
+			r, v = &lon, p.Lon()
+		}
+
+		var mid = (r[0] + r[1]) / 2
+		ch <<= 1
+
+		if v >= mid {
+			ch |= 1
+			r[0] = mid
+		} else {
+			r[1] = mid
+		}
+
+		even = !even
+
+		if bit++; bit == 5 {
+			hash = append(hash, geohashAlphabet[ch])
+			bit, ch = 0, 0
+		}
+	}
+
+	return string(hash)
+}
+
+// DecodeGeohash decodes the center of the geohash cell
+func DecodeGeohash(hash string) (Point, error) {
+	var box, err = GeohashBounds(hash)
+
+	if err != nil {
+		return Point{}, err
+	}
+
+	var upperLeft, lowerRight = box.Coordinates[0], box.Coordinates[1]
+
+	return NewPoint(
+		(upperLeft.Lat()+lowerRight.Lat())/2,
+		(upperLeft.Lon()+lowerRight.Lon())/2), nil
+}
+
+// GeohashBounds decodes the geohash cell as a bounding box
+func GeohashBounds(hash string) (BoundingBox, error) {
+	if hash == "" {
+		return BoundingBox{}, fmt.Errorf("geo: empty geohash")
+	}
+
+	var lat, lon = [2]float64{-90, 90}, [2]float64{-180, 180}
+	var even = true
+
+	for _, c := range strings.ToLower(hash) {
+		var ch = strings.IndexRune(geohashAlphabet, c)
+
+		if ch == -1 {
+			return BoundingBox{}, fmt.Errorf("geo: invalid geohash %q", hash)
+		}
+
+		for mask := 16; mask != 0; mask >>= 1 {
+			var r = &lat
+
+			if even {
+				r = &lon
+			}
+
+			var mid = (r[0] + r[1]) / 2
+
+			if ch&mask != 0 {
+				r[0] = mid
+			} else {
+				r[1] = mid
+			}
+
+			even = !even
+		}
+	}
+
+	return NewBoundingBox(NewPoint(lat[1], lon[0]), NewPoint(lat[0], lon[1])), nil
+}
@@ -0,0 +1,78 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geo
+
+import "testing"
+
+func TestEncodeGeohash(t *testing.T) {
+	var cases = []struct {
+		point     Point
+		precision int
+		want      string
+	}{
+		{NewPoint(57.64911, 10.40744), 11, "u4pruydqqvj"},
+		{NewPoint(57.64911, 10.40744), 5, "u4pru"},
+		{NewPoint(57.64911, 10.40744), 0, "u"},
+		{NewPoint(57.64911, 10.40744), 20, "u4pruydqqvj8"},
+		{NewPoint(-23.5505, -46.6333), 6, "6gyf4b"},
+	}
+
+	for _, c := range cases {
+		if got := EncodeGeohash(c.point, c.precision); got != c.want {
+			t.Errorf("Expected geohash %v, got %v instead", c.want, got)
+		}
+	}
+}
+
+func TestDecodeGeohash(t *testing.T) {
+	var got, err = DecodeGeohash("u4pruydqqvj")
+
+	if err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	assertAlmostEqual(t, 57.64911, got.Lat(), 1e-5)
+	assertAlmostEqual(t, 10.40744, got.Lon(), 1e-5)
+}
+
+func TestGeohashBounds(t *testing.T) {
+	var got, err = GeohashBounds("EZS42")
+
+	if err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	assertAlmostEqual(t, 42.626953125, got.Coordinates[0].Lat(), 1e-9)
+	assertAlmostEqual(t, -5.625, got.Coordinates[0].Lon(), 1e-9)
+	assertAlmostEqual(t, 42.5830078125, got.Coordinates[1].Lat(), 1e-9)
+	assertAlmostEqual(t, -5.5810546875, got.Coordinates[1].Lon(), 1e-9)
+
+	if !got.Contains(NewPoint(42.605, -5.603)) {
+		t.Errorf("Expected geohash cell to contain its center")
+	}
+}
+
+func TestGeohashRoundTrip(t *testing.T) {
+	var p = NewPoint(-33.8688, 151.2093)
+	var box, err = GeohashBounds(EncodeGeohash(p, 8))
+
+	if err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	if !box.Contains(p) {
+		t.Errorf("Expected geohash cell %v to contain %v", box, p)
+	}
+}
+
+func TestGeohashErrors(t *testing.T) {
+	if _, err := DecodeGeohash(""); err == nil {
+		t.Errorf("Expected error decoding empty geohash")
+	}
+
+	if _, err := GeohashBounds("u4pa"); err == nil {
+		t.Errorf("Expected error decoding geohash with invalid character")
+	}
+}
@@ -0,0 +1,533 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geo
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Feature is a GeoJSON feature: a geometry with a set of properties.
+// It is marshaled to and unmarshaled from GeoJSON (RFC 7946).
+type Feature struct {
+	ID         interface{}
+	Geometry   Geometry
+	Properties map[string]interface{}
+}
+
+// FeatureCollection is a GeoJSON collection of features.
+// It is marshaled to and unmarshaled from GeoJSON (RFC 7946).
+type FeatureCollection struct {
+	Features []Feature
+}
+
+type geoJSONObject struct {
+	Type        string                 `json:"type"`
+	ID          interface{}            `json:"id,omitempty"`
+	Coordinates json.RawMessage        `json:"coordinates,omitempty"`
+	Geometries  []json.RawMessage      `json:"geometries,omitempty"`
+	Geometry    json.RawMessage        `json:"geometry,omitempty"`
+	Properties  map[string]interface{} `json:"properties,omitempty"`
+	Features    []json.RawMessage      `json:"features,omitempty"`
+}
+
+type geoJSONGeometry struct {
+	Type        string      `json:"type"`
+	Coordinates interface{} `json:"coordinates"`
+}
+
+type geoJSONGeometryCollection struct {
+	Type       string        `json:"type"`
+	Geometries []interface{} `json:"geometries"`
+}
+
+type geoJSONFeature struct {
+	Type       string                 `json:"type"`
+	ID         interface{}            `json:"id,omitempty"`
+	Geometry   interface{}            `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type geoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []geoJSONFeature `json:"features"`
+}
+
+// NewFeature creates a new feature
+func NewFeature(geometry Geometry, properties map[string]interface{}) Feature {
+	return Feature{
+		Geometry:   geometry,
+		Properties: properties,
+	}
+}
+
+// NewFeatureCollection creates a new collection of features
+func NewFeatureCollection(features ...Feature) FeatureCollection {
+	return FeatureCollection{
+		Features: features,
+	}
+}
+
+// Geometries of the features in the collection, skipping features without one
+func (fc FeatureCollection) Geometries() []Geometry {
+	var geometries []Geometry
+
+	for _, f := range fc.Features {
+		if f.Geometry != nil {
+			geometries = append(geometries, f.Geometry)
+		}
+	}
+
+	return geometries
+}
+
+// MarshalJSON encodes the feature as GeoJSON
+func (f Feature) MarshalJSON() ([]byte, error) {
+	var g, err = f.geoJSON()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(g)
+}
+
+// UnmarshalJSON decodes the feature from GeoJSON
+func (f *Feature) UnmarshalJSON(data []byte) error {
+	var o geoJSONObject
+
+	if err := json.Unmarshal(data, &o); err != nil {
+		return err
+	}
+
+	var feature, err = o.feature()
+
+	if err != nil {
+		return err
+	}
+
+	*f = feature
+	return nil
+}
+
+// MarshalJSON encodes the feature collection as GeoJSON
+func (fc FeatureCollection) MarshalJSON() ([]byte, error) {
+	var g, err = fc.geoJSON()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(g)
+}
+
+// UnmarshalJSON decodes the feature collection from GeoJSON
+func (fc *FeatureCollection) UnmarshalJSON(data []byte) error {
+	var o geoJSONObject
+
+	if err := json.Unmarshal(data, &o); err != nil {
+		return err
+	}
+
+	var collection, err = o.featureCollection()
+
+	if err != nil {
+		return err
+	}
+
+	*fc = collection
+	return nil
+}
+
+// MarshalGeoJSON encodes a Geometry, Feature or FeatureCollection as GeoJSON.
+// Positions are written as [longitude, latitude], as required by RFC 7946.
+// A BoundingBox is written as a Polygon and a Circle is not supported.
+func MarshalGeoJSON(v interface{}) ([]byte, error) {
+	switch t := v.(type) {
+	case Feature:
+		return t.MarshalJSON()
+	case *Feature:
+		return t.MarshalJSON()
+	case FeatureCollection:
+		return t.MarshalJSON()
+	case *FeatureCollection:
+		return t.MarshalJSON()
+	case Geometry:
+		var g, err = geometryToGeoJSON(t)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return json.Marshal(g)
+	}
+
+	return nil, fmt.Errorf("geo: can't encode %T as GeoJSON", v)
+}
+
+// UnmarshalGeoJSON decodes a GeoJSON object.
+// It returns a Geometry, a Feature, or a FeatureCollection.
+// Altitudes are discarded.
+func UnmarshalGeoJSON(data []byte) (interface{}, error) {
+	var o geoJSONObject
+
+	if err := json.Unmarshal(data, &o); err != nil {
+		return nil, err
+	}
+
+	switch o.Type {
+	case "Feature":
+		return o.feature()
+	case "FeatureCollection":
+		return o.featureCollection()
+	}
+
+	return o.geometry()
+}
+
+// UnmarshalGeoJSONGeometry decodes a GeoJSON geometry object
+func UnmarshalGeoJSONGeometry(data []byte) (Geometry, error) {
+	var o geoJSONObject
+
+	if err := json.Unmarshal(data, &o); err != nil {
+		return nil, err
+	}
+
+	return o.geometry()
+}
+
+func (f Feature) geoJSON() (geoJSONFeature, error) {
+	var g = geoJSONFeature{
+		Type:       "Feature",
+		ID:         f.ID,
+		Properties: f.Properties,
+	}
+
+	if f.Geometry == nil {
+		return g, nil
+	}
+
+	var geometry, err = geometryToGeoJSON(f.Geometry)
+	g.Geometry = geometry
+	return g, err
+}
+
+func (fc FeatureCollection) geoJSON() (geoJSONFeatureCollection, error) {
+	var g = geoJSONFeatureCollection{
+		Type:     "FeatureCollection",
+		Features: []geoJSONFeature{},
+	}
+
+	for _, f := range fc.Features {
+		var feature, err = f.geoJSON()
+
+		if err != nil {
+			return g, err
+		}
+
+		g.Features = append(g.Features, feature)
+	}
+
+	return g, nil
+}
+
+func geometryToGeoJSON(g Geometry) (interface{}, error) {
+	switch t := g.(type) {
+	case Point:
+		return &geoJSONGeometry{"Point", t.position()}, nil
+	case MultiPoint:
+		return &geoJSONGeometry{"MultiPoint", positions(t.Coordinates)}, nil
+	case Line:
+		return &geoJSONGeometry{"LineString", positions(t.Coordinates)}, nil
+	case MultiLine:
+		return &geoJSONGeometry{"MultiLineString", rings(t.Coordinates)}, nil
+	case Polygon:
+		return &geoJSONGeometry{"Polygon", rings(t.Coordinates)}, nil
+	case MultiPolygon:
+		var coords = [][][][2]float64{}
+
+		for _, p := range t.Coordinates {
+			coords = append(coords, rings(p))
+		}
+
+		return &geoJSONGeometry{"MultiPolygon", coords}, nil
+	case BoundingBox:
+		return boundingBoxToGeoJSON(t)
+	case GeometryCollection:
+		var geometries = []interface{}{}
+
+		for _, c := range t.Geometries {
+			var gc, err = geometryToGeoJSON(c)
+
+			if err != nil {
+				return nil, err
+			}
+
+			geometries = append(geometries, gc)
+		}
+
+		return &geoJSONGeometryCollection{"GeometryCollection", geometries}, nil
+	}
+
+	return nil, fmt.Errorf("geo: can't encode %T as GeoJSON", g)
+}
+
+func boundingBoxToGeoJSON(b BoundingBox) (interface{}, error) {
+	if len(b.Coordinates) != 2 {
+		return nil, fmt.Errorf("geo: bounding box must have 2 points, got %d",
+			len(b.Coordinates))
+	}
+
+	var upperLeft, lowerRight = b.Coordinates[0], b.Coordinates[1]
+	var ring = []Point{
+		upperLeft,
+		NewPoint(lowerRight[0], upperLeft[1]),
+		lowerRight,
+		NewPoint(upperLeft[0], lowerRight[1]),
+		upperLeft,
+	}
+
+	return &geoJSONGeometry{"Polygon", rings([][]Point{ring})}, nil
+}
+
+func (p Point) position() [2]float64 {
+	return [2]float64{p[1], p[0]}
+}
+
+func positions(points []Point) [][2]float64 {
+	var coords = [][2]float64{}
+
+	for _, p := range points {
+		coords = append(coords, p.position())
+	}
+
+	return coords
+}
+
+func rings(lines [][]Point) [][][2]float64 {
+	var coords = [][][2]float64{}
+
+	for _, l := range lines {
+		coords = append(coords, positions(l))
+	}
+
+	return coords
+}
+
+func (o geoJSONObject) feature() (Feature, error) {
+	var f = Feature{
+		ID:         o.ID,
+		Properties: o.Properties,
+	}
+
+	if o.Type != "Feature" {
+		return f, fmt.Errorf("geo: expected GeoJSON Feature, got %q", o.Type)
+	}
+
+	if len(o.Geometry) == 0 || string(o.Geometry) == "null" {
+		return f, nil
+	}
+
+	var g, err = UnmarshalGeoJSONGeometry(o.Geometry)
+	f.Geometry = g
+	return f, err
+}
+
+func (o geoJSONObject) featureCollection() (FeatureCollection, error) {
+	var fc FeatureCollection
+
+	if o.Type != "FeatureCollection" {
+		return fc, fmt.Errorf("geo: expected GeoJSON FeatureCollection, got %q",
+			o.Type)
+	}
+
+	for _, raw := range o.Features {
+		var f Feature
+
+		if err := json.Unmarshal(raw, &f); err != nil {
+			return fc, err
+		}
+
+		fc.Features = append(fc.Features, f)
+	}
+
+	return fc, nil
+}
+
+func (o geoJSONObject) geometry() (Geometry, error) {
+	switch o.Type {
+	case "Point":
+		var pos []float64
+
+		if err := o.decodeCoordinates(&pos); err != nil {
+			return nil, err
+		}
+
+		var point, err = fromPosition(pos)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return point, nil
+	case "MultiPoint":
+		var pos [][]float64
+
+		if err := o.decodeCoordinates(&pos); err != nil {
+			return nil, err
+		}
+
+		var points, err = fromPositions(pos)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return NewMultiPoint(points...), nil
+	case "LineString":
+		var pos [][]float64
+
+		if err := o.decodeCoordinates(&pos); err != nil {
+			return nil, err
+		}
+
+		var points, err = fromPositions(pos)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return NewLine(points...), nil
+	case "MultiLineString":
+		var pos [][][]float64
+
+		if err := o.decodeCoordinates(&pos); err != nil {
+			return nil, err
+		}
+
+		var lines, err = fromRings(pos)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return MultiLine{Type: "multilinestring", Coordinates: lines}, nil
+	case "Polygon":
+		var pos [][][]float64
+
+		if err := o.decodeCoordinates(&pos); err != nil {
+			return nil, err
+		}
+
+		var r, err = fromRings(pos)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return Polygon{Type: "polygon", Coordinates: r}, nil
+	case "MultiPolygon":
+		return o.multiPolygon()
+	case "GeometryCollection":
+		return o.geometryCollection()
+	}
+
+	return nil, fmt.Errorf("geo: unknown GeoJSON geometry type %q", o.Type)
+}
+
+func (o geoJSONObject) multiPolygon() (Geometry, error) {
+	var pos [][][][]float64
+
+	if err := o.decodeCoordinates(&pos); err != nil {
+		return nil, err
+	}
+
+	var mp = MultiPolygon{
+		Type:        "multipolygon",
+		Coordinates: [][][]Point{},
+	}
+
+	for _, p := range pos {
+		var r, err = fromRings(p)
+
+		if err != nil {
+			return nil, err
+		}
+
+		mp.Coordinates = append(mp.Coordinates, r)
+	}
+
+	return mp, nil
+}
+
+func (o geoJSONObject) geometryCollection() (Geometry, error) {
+	var gc = NewGeometryCollection()
+	gc.Geometries = []Geometry{}
+
+	for _, raw := range o.Geometries {
+		var g, err = UnmarshalGeoJSONGeometry(raw)
+
+		if err != nil {
+			return nil, err
+		}
+
+		gc.Geometries = append(gc.Geometries, g)
+	}
+
+	return gc, nil
+}
+
+func (o geoJSONObject) decodeCoordinates(v interface{}) error {
+	if len(o.Coordinates) == 0 {
+		return fmt.Errorf("geo: GeoJSON %s has no coordinates", o.Type)
+	}
+
+	if err := json.Unmarshal(o.Coordinates, v); err != nil {
+		return fmt.Errorf("geo: invalid GeoJSON %s coordinates: %v", o.Type, err)
+	}
+
+	return nil
+}
+
+func fromPosition(pos []float64) (Point, error) {
+	if len(pos) < 2 {
+		return Point{}, fmt.Errorf("geo: GeoJSON position must have 2 or more elements, got %d",
+			len(pos))
+	}
+
+	return NewPoint(pos[1], pos[0]), nil
+}
+
+func fromPositions(pos [][]float64) ([]Point, error) {
+	var points = []Point{}
+
+	for _, p := range pos {
+		var point, err = fromPosition(p)
+
+		if err != nil {
+			return nil, err
+		}
+
+		points = append(points, point)
+	}
+
+	return points, nil
+}
+
+func fromRings(pos [][][]float64) ([][]Point, error) {
+	var lines = [][]Point{}
+
+	for _, r := range pos {
+		var points, err = fromPositions(r)
+
+		if err != nil {
+			return nil, err
+		}
+
+		lines = append(lines, points)
+	}
+
+	return lines, nil
+}
@@ -0,0 +1,300 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geo
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/henvic/wedeploy-sdk-go/jsonlib"
+)
+
+func TestMarshalGeoJSONPoint(t *testing.T) {
+	var want = `{"type":"Point","coordinates":[20,10]}`
+	assertGeoJSON(t, want, NewPoint(10, 20))
+}
+
+func TestMarshalGeoJSONLine(t *testing.T) {
+	var want = `{"type":"LineString","coordinates":[[20,10],[30,10]]}`
+	assertGeoJSON(t, want, NewLine(NewPoint(10, 20), NewPoint(10, 30)))
+}
+
+func TestMarshalGeoJSONPolygon(t *testing.T) {
+	var want = `{
+    "type": "Polygon",
+    "coordinates": [
+        [[0, 0], [30, 0], [0, 40], [0, 0]],
+        [[5, 5], [8, 5], [5, 9], [5, 5]]
+    ]
+}`
+
+	var polygon = NewPolygon(
+		NewPoint(0, 0),
+		NewPoint(0, 30),
+		NewPoint(40, 0),
+		NewPoint(0, 0))
+
+	polygon.AddHole(
+		NewPoint(5, 5),
+		NewPoint(5, 8),
+		NewPoint(9, 5),
+		NewPoint(5, 5))
+
+	assertGeoJSON(t, want, polygon)
+}
+
+func TestMarshalGeoJSONMultiPolygon(t *testing.T) {
+	var want = `{
+    "type": "MultiPolygon",
+    "coordinates": [
+        [[[0, 0], [1, 0], [0, 1], [0, 0]]]
+    ]
+}`
+
+	var mp = NewMultiPolygon(NewPolygon(
+		NewPoint(0, 0),
+		NewPoint(0, 1),
+		NewPoint(1, 0),
+		NewPoint(0, 0)))
+
+	assertGeoJSON(t, want, mp)
+}
+
+func TestMarshalGeoJSONBoundingBox(t *testing.T) {
+	var want = `{
+    "type": "Polygon",
+    "coordinates": [
+        [[0, 20], [0, 0], [20, 0], [20, 20], [0, 20]]
+    ]
+}`
+
+	assertGeoJSON(t, want,
+		NewBoundingBox(NewPoint(20, 0), NewPoint(0, 20)))
+}
+
+func TestMarshalGeoJSONGeometryCollection(t *testing.T) {
+	var want = `{
+    "type": "GeometryCollection",
+    "geometries": [
+        {"type": "Point", "coordinates": [20, 10]},
+        {"type": "MultiPoint", "coordinates": [[1, 0], [3, 2]]}
+    ]
+}`
+
+	var gc = NewGeometryCollection(
+		NewPoint(10, 20),
+		NewMultiPoint(NewPoint(0, 1), NewPoint(2, 3)))
+
+	assertGeoJSON(t, want, gc)
+}
+
+func TestMarshalGeoJSONCircle(t *testing.T) {
+	var _, err = MarshalGeoJSON(NewCircle(NewPoint(0, 0), "2km"))
+
+	if err == nil {
+		t.Errorf("Expected error for circle, got nil instead")
+	}
+}
+
+func TestMarshalGeoJSONUnknown(t *testing.T) {
+	var _, err = MarshalGeoJSON("foo")
+
+	if err == nil {
+		t.Errorf("Expected error for unknown type, got nil instead")
+	}
+}
+
+func TestFeature(t *testing.T) {
+	var want = `{
+    "type": "Feature",
+    "id": "store-1",
+    "geometry": {"type": "Point", "coordinates": [-46.6, -23.5]},
+    "properties": {"name": "São Paulo"}
+}`
+
+	var f = NewFeature(NewPoint(-23.5, -46.6), map[string]interface{}{
+		"name": "São Paulo",
+	})
+
+	f.ID = "store-1"
+
+	jsonlib.AssertJSONMarshal(t, want, f)
+}
+
+func TestFeatureNullGeometry(t *testing.T) {
+	var want = `{"type":"Feature","geometry":null,"properties":null}`
+	jsonlib.AssertJSONMarshal(t, want, Feature{})
+}
+
+func TestFeatureCollection(t *testing.T) {
+	var want = `{
+    "type": "FeatureCollection",
+    "features": [
+        {
+            "type": "Feature",
+            "geometry": {"type": "Point", "coordinates": [2, 1]},
+            "properties": {}
+        }
+    ]
+}`
+
+	var fc = NewFeatureCollection(
+		NewFeature(NewPoint(1, 2), map[string]interface{}{}))
+
+	jsonlib.AssertJSONMarshal(t, want, fc)
+}
+
+func TestFeatureCollectionGeometries(t *testing.T) {
+	var fc = NewFeatureCollection(
+		NewFeature(NewPoint(1, 2), nil),
+		NewFeature(nil, nil),
+		NewFeature(NewLine(NewPoint(0, 0), NewPoint(1, 1)), nil))
+
+	var got = fc.Geometries()
+
+	if len(got) != 2 {
+		t.Errorf("Expected 2 geometries, got %d instead", len(got))
+	}
+}
+
+func TestUnmarshalGeoJSONGeometries(t *testing.T) {
+	var cases = []struct {
+		geojson string
+		want    Geometry
+	}{
+		{
+			`{"type":"Point","coordinates":[20,10,300]}`,
+			NewPoint(10, 20),
+		},
+		{
+			`{"type":"MultiPoint","coordinates":[[20,10],[30,10]]}`,
+			NewMultiPoint(NewPoint(10, 20), NewPoint(10, 30)),
+		},
+		{
+			`{"type":"LineString","coordinates":[[20,10],[30,10]]}`,
+			NewLine(NewPoint(10, 20), NewPoint(10, 30)),
+		},
+		{
+			`{"type":"MultiLineString","coordinates":[[[20,10],[30,10]]]}`,
+			NewMultiLine(NewLine(NewPoint(10, 20), NewPoint(10, 30))),
+		},
+		{
+			`{"type":"Polygon","coordinates":[[[0,0],[1,0],[0,1],[0,0]]]}`,
+			NewPolygon(NewPoint(0, 0), NewPoint(0, 1), NewPoint(1, 0), NewPoint(0, 0)),
+		},
+		{
+			`{"type":"MultiPolygon","coordinates":[[[[0,0],[1,0],[0,1],[0,0]]]]}`,
+			NewMultiPolygon(NewPolygon(
+				NewPoint(0, 0), NewPoint(0, 1), NewPoint(1, 0), NewPoint(0, 0))),
+		},
+		{
+			`{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[1,2]}]}`,
+			NewGeometryCollection(NewPoint(2, 1)),
+		},
+	}
+
+	for _, c := range cases {
+		var got, err = UnmarshalGeoJSON([]byte(c.geojson))
+
+		if err != nil {
+			t.Errorf("Expected no error decoding %s, got %v instead", c.geojson, err)
+		}
+
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("Expected %s to decode to %v, got %v instead",
+				c.geojson, c.want, got)
+		}
+	}
+}
+
+func TestUnmarshalGeoJSONFeatureCollection(t *testing.T) {
+	var data = `{
+    "type": "FeatureCollection",
+    "features": [
+        {
+            "type": "Feature",
+            "id": 1,
+            "geometry": {"type": "Point", "coordinates": [102.0, 0.5]},
+            "properties": {"prop0": "value0"}
+        },
+        {
+            "type": "Feature",
+            "geometry": null,
+            "properties": null
+        }
+    ]
+}`
+
+	var got, err = UnmarshalGeoJSON([]byte(data))
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v instead", err)
+	}
+
+	var want = NewFeatureCollection(
+		Feature{
+			ID:         float64(1),
+			Geometry:   NewPoint(0.5, 102),
+			Properties: map[string]interface{}{"prop0": "value0"},
+		},
+		Feature{})
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v instead", want, got)
+	}
+}
+
+func TestUnmarshalGeoJSONFeatureRoundTrip(t *testing.T) {
+	var want = NewFeature(NewPolygon(
+		NewPoint(0, 0), NewPoint(0, 1), NewPoint(1, 0), NewPoint(0, 0)),
+		map[string]interface{}{"name": "triangle"})
+
+	var bin, err = json.Marshal(want)
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v instead", err)
+	}
+
+	var got Feature
+
+	if err = json.Unmarshal(bin, &got); err != nil {
+		t.Fatalf("Expected no error, got %v instead", err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v instead", want, got)
+	}
+}
+
+func TestUnmarshalGeoJSONErrors(t *testing.T) {
+	var cases = []string{
+		`[]`,
+		`{"type":"Circle","coordinates":[0,0]}`,
+		`{"type":"Point"}`,
+		`{"type":"Point","coordinates":[1]}`,
+		`{"type":"LineString","coordinates":[[1,2],[3]]}`,
+		`{"type":"Polygon","coordinates":"foo"}`,
+		`{"type":"GeometryCollection","geometries":[{"type":"Foo"}]}`,
+		`{"type":"Feature","geometry":{"type":"Foo"}}`,
+		`{"type":"FeatureCollection","features":[{"type":"Point"}]}`,
+	}
+
+	for _, c := range cases {
+		if _, err := UnmarshalGeoJSON([]byte(c)); err == nil {
+			t.Errorf("Expected error decoding %s, got nil instead", c)
+		}
+	}
+}
+
+func assertGeoJSON(t *testing.T, want string, v interface{}) {
+	var bin, err = MarshalGeoJSON(v)
+
+	if err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	jsonlib.AssertJSONMarshal(t, want, json.RawMessage(bin))
+}
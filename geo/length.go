@@ -0,0 +1,196 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geo
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Unit of length
+type Unit string
+
+// Units of length
+const (
+	Millimeters   Unit = "mm"
+	Centimeters   Unit = "cm"
+	Meters        Unit = "m"
+	Kilometers    Unit = "km"
+	Inches        Unit = "in"
+	Feet          Unit = "ft"
+	Yards         Unit = "yd"
+	Miles         Unit = "mi"
+	NauticalMiles Unit = "nmi"
+)
+
+var meters = map[Unit]float64{
+	Millimeters:   0.001,
+	Centimeters:   0.01,
+	Meters:        1,
+	Kilometers:    1000,
+	Inches:        0.0254,
+	Feet:          0.3048,
+	Yards:         0.9144,
+	Miles:         1609.344,
+	NauticalMiles: 1852,
+}
+
+var unitAliases = map[string]Unit{
+	"millimeter":    Millimeters,
+	"millimeters":   Millimeters,
+	"centimeter":    Centimeters,
+	"centimeters":   Centimeters,
+	"inch":          Inches,
+	"inches":        Inches,
+	"meter":         Meters,
+	"meters":        Meters,
+	"kilometer":     Kilometers,
+	"kilometers":    Kilometers,
+	"mile":          Miles,
+	"miles":         Miles,
+	"yard":          Yards,
+	"yards":         Yards,
+	"foot":          Feet,
+	"feet":          Feet,
+	"nauticalmile":  NauticalMiles,
+	"nauticalmiles": NauticalMiles,
+	"nm":            NauticalMiles,
+}
+
+// Length is a distance measured in a unit of length, such as 5km
+type Length struct {
+	Value float64
+	Unit  Unit
+}
+
+// NewLength creates a new length
+func NewLength(value float64, unit Unit) Length {
+	return Length{
+		Value: value,
+		Unit:  unit,
+	}
+}
+
+// ParseLength parses a length such as "5km", "3 mi", or "500".
+// A value without unit is in meters.
+func ParseLength(s string) (Length, error) {
+	var trimmed = strings.TrimSpace(s)
+	var i = strings.IndexFunc(trimmed, func(r rune) bool {
+		return !strings.ContainsRune("0123456789.+-", r)
+	})
+
+	if i == -1 {
+		i = len(trimmed)
+	}
+
+	var value, err = strconv.ParseFloat(trimmed[:i], 64)
+
+	if err != nil {
+		return Length{}, fmt.Errorf("geo: invalid length %q", s)
+	}
+
+	var unit, ue = ParseUnit(trimmed[i:])
+
+	if ue != nil {
+		return Length{}, ue
+	}
+
+	var l = NewLength(value, unit)
+	return l, l.Validate()
+}
+
+// ParseUnit parses a unit of length, such as "km" or "miles".
+// An empty unit is in meters.
+func ParseUnit(s string) (Unit, error) {
+	var name = strings.ToLower(strings.TrimSpace(s))
+
+	if name == "" {
+		return Meters, nil
+	}
+
+	if _, ok := meters[Unit(name)]; ok {
+		return Unit(name), nil
+	}
+
+	if unit, ok := unitAliases[name]; ok {
+		return unit, nil
+	}
+
+	return "", fmt.Errorf("geo: unknown unit of length %q", s)
+}
+
+// Meters is the length in meters
+func (l Length) Meters() float64 {
+	return l.Value * meters[l.Unit]
+}
+
+// Convert the length to another unit of length
+func (l Length) Convert(unit Unit) (Length, error) {
+	if err := l.Validate(); err != nil {
+		return Length{}, err
+	}
+
+	var factor, ok = meters[unit]
+
+	if !ok {
+		return Length{}, fmt.Errorf("geo: unknown unit of length %q", unit)
+	}
+
+	return NewLength(l.Meters()/factor, unit), nil
+}
+
+// String formats the length, such as "5km"
+func (l Length) String() string {
+	return strconv.FormatFloat(l.Value, 'f', -1, 64) + string(l.Unit)
+}
+
+// Validate the length
+func (l Length) Validate() error {
+	if _, ok := meters[l.Unit]; !ok {
+		return fmt.Errorf("geo: unknown unit of length %q", l.Unit)
+	}
+
+	if math.IsNaN(l.Value) || math.IsInf(l.Value, 0) || l.Value < 0 {
+		return fmt.Errorf("geo: invalid length value %v", l.Value)
+	}
+
+	return nil
+}
+
+// MarshalJSON encodes a valid length as a string, such as "5km"
+func (l Length) MarshalJSON() ([]byte, error) {
+	if err := l.Validate(); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(l.String())
+}
+
+// UnmarshalJSON decodes a length from a string or from a number of meters
+func (l *Length) UnmarshalJSON(data []byte) error {
+	var s string
+
+	if err := json.Unmarshal(data, &s); err != nil {
+		var f float64
+
+		if err := json.Unmarshal(data, &f); err != nil {
+			return fmt.Errorf("geo: invalid length %s", data)
+		}
+
+		s = strconv.FormatFloat(f, 'f', -1, 64)
+	}
+
+	var parsed, err = ParseLength(s)
+
+	if err != nil {
+		return err
+	}
+
+	*l = parsed
+	return nil
+}
@@ -0,0 +1,136 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geo
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/henvic/wedeploy-sdk-go/jsonlib"
+)
+
+func TestParseLength(t *testing.T) {
+	var cases = []struct {
+		s    string
+		want Length
+	}{
+		{"5km", NewLength(5, Kilometers)},
+		{"3mi", NewLength(3, Miles)},
+		{"500m", NewLength(500, Meters)},
+		{"500", NewLength(500, Meters)},
+		{" 2.5 NMI ", NewLength(2.5, NauticalMiles)},
+		{"10 feet", NewLength(10, Feet)},
+		{"7yd", NewLength(7, Yards)},
+		{"1 kilometers", NewLength(1, Kilometers)},
+		{"15cm", NewLength(15, Centimeters)},
+		{"4mm", NewLength(4, Millimeters)},
+		{"12in", NewLength(12, Inches)},
+		{"3 inches", NewLength(3, Inches)},
+		{"2NM", NewLength(2, NauticalMiles)},
+	}
+
+	for _, c := range cases {
+		var got, err = ParseLength(c.s)
+
+		if err != nil {
+			t.Errorf("Expected no error parsing %q, got %v instead", c.s, err)
+		}
+
+		if got != c.want {
+			t.Errorf("Expected %q to be parsed as %v, got %v instead", c.s, c.want, got)
+		}
+	}
+}
+
+func TestParseLengthErrors(t *testing.T) {
+	var cases = []string{"", "km", "5lightyears", "-1km", "1..2m", "5 k m"}
+
+	for _, c := range cases {
+		if _, err := ParseLength(c); err == nil {
+			t.Errorf("Expected error parsing %q, got nil instead", c)
+		}
+	}
+}
+
+func TestLengthString(t *testing.T) {
+	var want = "2.5km"
+
+	if got := NewLength(2.5, Kilometers).String(); got != want {
+		t.Errorf("Expected %v, got %v instead", want, got)
+	}
+}
+
+func TestLengthConvert(t *testing.T) {
+	var cases = []struct {
+		l    Length
+		unit Unit
+		want float64
+	}{
+		{NewLength(1, Miles), Kilometers, 1.609344},
+		{NewLength(1, NauticalMiles), Meters, 1852},
+		{NewLength(3, Feet), Yards, 1},
+		{NewLength(1000, Meters), Kilometers, 1},
+	}
+
+	for _, c := range cases {
+		var got, err = c.l.Convert(c.unit)
+
+		if err != nil {
+			t.Errorf("Expected no error, got %v instead", err)
+		}
+
+		if got.Unit != c.unit || math.Abs(got.Value-c.want) > 1e-9 {
+			t.Errorf("Expected %v in %v to be %v, got %v instead",
+				c.l, c.unit, c.want, got)
+		}
+	}
+}
+
+func TestLengthConvertErrors(t *testing.T) {
+	if _, err := NewLength(1, "ly").Convert(Meters); err == nil {
+		t.Errorf("Expected error converting from unknown unit")
+	}
+
+	if _, err := NewLength(1, Meters).Convert("ly"); err == nil {
+		t.Errorf("Expected error converting to unknown unit")
+	}
+}
+
+func TestLengthMeters(t *testing.T) {
+	assertAlmostEqual(t, 4828.032, NewLength(3, Miles).Meters(), 1e-9)
+}
+
+func TestLengthMarshalJSON(t *testing.T) {
+	jsonlib.AssertJSONMarshal(t, `"5km"`, NewLength(5, Kilometers))
+}
+
+func TestLengthMarshalJSONInvalid(t *testing.T) {
+	if _, err := json.Marshal(NewLength(5, "parsecs")); err == nil {
+		t.Errorf("Expected error marshaling length with unknown unit")
+	}
+}
+
+func TestLengthUnmarshalJSON(t *testing.T) {
+	var got []Length
+
+	if err := json.Unmarshal([]byte(`["5km", 20]`), &got); err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	if len(got) != 2 ||
+		got[0] != NewLength(5, Kilometers) ||
+		got[1] != NewLength(20, Meters) {
+		t.Errorf("Unexpected lengths %v", got)
+	}
+
+	if err := json.Unmarshal([]byte(`"5x"`), &got); err == nil {
+		t.Errorf("Expected error unmarshaling invalid length")
+	}
+
+	if err := json.Unmarshal([]byte(`true`), &got); err == nil {
+		t.Errorf("Expected error unmarshaling invalid length")
+	}
+}
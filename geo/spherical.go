@@ -0,0 +1,296 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// EarthRadius is the mean radius of the Earth, in meters
+const EarthRadius = 6371008.8
+
+// ErrEmptyGeometry is used when a geometry has no points
+var ErrEmptyGeometry = errors.New("geo: empty geometry")
+
+// Distance is the great-circle distance between two points, in meters
+func Distance(a, b Point) float64 {
+	var lat1, lat2 = radians(a.Lat()), radians(b.Lat())
+	var dLat = lat2 - lat1
+	var dLon = radians(b.Lon() - a.Lon())
+
+	var h = math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return 2 * EarthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// Bearing is the initial bearing from a to b,
+// in degrees clockwise from the north, in the [0, 360) interval
+func Bearing(a, b Point) float64 {
+	var lat1, lat2 = radians(a.Lat()), radians(b.Lat())
+	var dLon = radians(b.Lon() - a.Lon())
+
+	var y = math.Sin(dLon) * math.Cos(lat2)
+	var x = math.Cos(lat1)*math.Sin(lat2) -
+		math.Sin(lat1)*math.Cos(lat2)*math.Cos(dLon)
+
+	return math.Mod(degrees(math.Atan2(y, x))+360, 360)
+}
+
+// Destination is the point reached traveling the given distance (in meters)
+// from the origin along a great circle with the given initial bearing
+func Destination(origin Point, bearing, distance float64) Point {
+	var lat1, lon1 = radians(origin.Lat()), radians(origin.Lon())
+	var theta = radians(bearing)
+	var delta = distance / EarthRadius
+
+	var lat2 = math.Asin(math.Sin(lat1)*math.Cos(delta) +
+		math.Cos(lat1)*math.Sin(delta)*math.Cos(theta))
+	var lon2 = lon1 + math.Atan2(
+		math.Sin(theta)*math.Sin(delta)*math.Cos(lat1),
+		math.Cos(delta)-math.Sin(lat1)*math.Sin(lat2))
+
+	return NewPoint(degrees(lat2), math.Mod(degrees(lon2)+540, 360)-180)
+}
+
+// Contains checks if the point is inside the polygon and outside of its holes
+func (p Polygon) Contains(point Point) bool {
+	if len(p.Coordinates) == 0 || !ringContains(p.Coordinates[0], point) {
+		return false
+	}
+
+	for _, hole := range p.Coordinates[1:] {
+		if ringContains(hole, point) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Area of the polygon on the Earth's surface minus its holes, in square meters
+func (p Polygon) Area() float64 {
+	if len(p.Coordinates) == 0 {
+		return 0
+	}
+
+	var area = math.Abs(ringArea(p.Coordinates[0]))
+
+	for _, hole := range p.Coordinates[1:] {
+		area -= math.Abs(ringArea(hole))
+	}
+
+	return area
+}
+
+// Contains checks if the point is inside the bounding box.
+// A box whose upper left longitude is greater than its lower right longitude
+// is considered to cross the antimeridian.
+func (b BoundingBox) Contains(point Point) bool {
+	if len(b.Coordinates) != 2 {
+		return false
+	}
+
+	var upperLeft, lowerRight = b.Coordinates[0], b.Coordinates[1]
+
+	if point.Lat() > upperLeft.Lat() || point.Lat() < lowerRight.Lat() {
+		return false
+	}
+
+	if upperLeft.Lon() <= lowerRight.Lon() {
+		return point.Lon() >= upperLeft.Lon() && point.Lon() <= lowerRight.Lon()
+	}
+
+	return point.Lon() >= upperLeft.Lon() || point.Lon() <= lowerRight.Lon()
+}
+
+// Bounds is the smallest bounding box containing the geometry.
+// The bounds of a circle crossing the antimeridian have an upper left
+// longitude greater than their lower right longitude (see Contains), while
+// such a circle in a collection widens its bounds to all longitudes.
+func Bounds(g Geometry) (BoundingBox, error) {
+	if c, ok := g.(Circle); ok {
+		return circleBounds(c)
+	}
+
+	var points, err = pointsOf(g)
+
+	if err != nil {
+		return BoundingBox{}, err
+	}
+
+	if len(points) == 0 {
+		return BoundingBox{}, ErrEmptyGeometry
+	}
+
+	var north, west = points[0].Lat(), points[0].Lon()
+	var south, east = north, west
+
+	for _, point := range points[1:] {
+		north = math.Max(north, point.Lat())
+		south = math.Min(south, point.Lat())
+		west = math.Min(west, point.Lon())
+		east = math.Max(east, point.Lon())
+	}
+
+	return NewBoundingBox(NewPoint(north, west), NewPoint(south, east)), nil
+}
+
+func pointsOf(g Geometry) ([]Point, error) {
+	switch t := g.(type) {
+	case Point:
+		return []Point{t}, nil
+	case MultiPoint:
+		return t.Coordinates, nil
+	case Line:
+		return t.Coordinates, nil
+	case BoundingBox:
+		return t.Coordinates, nil
+	case Circle:
+		return circleExtremes(t)
+	case MultiLine:
+		return flatten(t.Coordinates), nil
+	case Polygon:
+		return flatten(t.Coordinates), nil
+	case MultiPolygon:
+		var points []Point
+
+		for _, polygon := range t.Coordinates {
+			points = append(points, flatten(polygon)...)
+		}
+
+		return points, nil
+	case GeometryCollection:
+		var points []Point
+
+		for _, c := range t.Geometries {
+			var cp, err = pointsOf(c)
+
+			if err != nil {
+				return nil, err
+			}
+
+			points = append(points, cp...)
+		}
+
+		return points, nil
+	}
+
+	return nil, fmt.Errorf("geo: can't compute bounds of %T", g)
+}
+
+func circleExtremes(c Circle) ([]Point, error) {
+	var b, err = circleBounds(c)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var upperLeft, lowerRight = b.Coordinates[0], b.Coordinates[1]
+
+	if upperLeft.Lon() > lowerRight.Lon() {
+		return []Point{NewPoint(upperLeft.Lat(), -180), NewPoint(lowerRight.Lat(), 180)}, nil
+	}
+
+	return b.Coordinates, nil
+}
+
+// circleBounds is the bounding box of the circle, whose widest longitudes
+// are away from the east and west of its center, except on the equator.
+// See "Finding Points Within a Distance of a Latitude/Longitude Using
+// Bounding Coordinates", Matuschek.
+func circleBounds(c Circle) (BoundingBox, error) {
+	var radius, err = c.RadiusLength()
+
+	if err != nil {
+		return BoundingBox{}, err
+	}
+
+	var r = radius.Meters() / EarthRadius
+	var lat, lon = radians(c.Coordinates.Lat()), radians(c.Coordinates.Lon())
+	var north, south = lat + r, lat - r
+
+	// a circle containing a pole contains all longitudes around it
+	if north >= math.Pi/2 || south <= -math.Pi/2 {
+		return NewBoundingBox(
+			NewPoint(degrees(math.Min(north, math.Pi/2)), -180),
+			NewPoint(degrees(math.Max(south, -math.Pi/2)), 180)), nil
+	}
+
+	var dLon = math.Asin(math.Sin(r) / math.Cos(lat))
+	var west, east = lon - dLon, lon + dLon
+
+	if west < -math.Pi {
+		west += 2 * math.Pi
+	}
+
+	if east > math.Pi {
+		east -= 2 * math.Pi
+	}
+
+	return NewBoundingBox(
+		NewPoint(degrees(north), degrees(west)),
+		NewPoint(degrees(south), degrees(east))), nil
+}
+
+func flatten(lines [][]Point) []Point {
+	var points []Point
+
+	for _, l := range lines {
+		points = append(points, l...)
+	}
+
+	return points
+}
+
+// ringContains uses the even-odd rule on the latitude/longitude plane
+func ringContains(ring []Point, point Point) bool {
+	var inside = false
+
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		var a, b = ring[i], ring[j]
+
+		if (a.Lat() > point.Lat()) != (b.Lat() > point.Lat()) &&
+			point.Lon() < (b.Lon()-a.Lon())*(point.Lat()-a.Lat())/
+				(b.Lat()-a.Lat())+a.Lon() {
+			inside = !inside
+		}
+	}
+
+	return inside
+}
+
+// ringArea is the signed spherical area of a ring, in square meters.
+// See "Some Algorithms for Polygons on a Sphere", Chamberlain and Duquette.
+func ringArea(ring []Point) float64 {
+	if len(ring) > 1 && ring[0] == ring[len(ring)-1] {
+		ring = ring[:len(ring)-1]
+	}
+
+	var n = len(ring)
+
+	if n < 3 {
+		return 0
+	}
+
+	var total float64
+
+	for i := range ring {
+		var prev, cur, next = ring[(i+n-1)%n], ring[i], ring[(i+1)%n]
+		total += radians(next.Lon()-prev.Lon()) * math.Sin(radians(cur.Lat()))
+	}
+
+	return total * EarthRadius * EarthRadius / 2
+}
+
+func radians(d float64) float64 {
+	return d * math.Pi / 180
+}
+
+func degrees(r float64) float64 {
+	return r * 180 / math.Pi
+}
@@ -0,0 +1,275 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+var (
+	paris  = NewPoint(48.8566, 2.3522)
+	london = NewPoint(51.5074, -0.1278)
+)
+
+func TestDistance(t *testing.T) {
+	assertAlmostEqual(t, 343556, Distance(paris, london), 100)
+	assertAlmostEqual(t, 343556, Distance(london, paris), 100)
+}
+
+func TestDistanceSamePoint(t *testing.T) {
+	assertAlmostEqual(t, 0, Distance(paris, paris), 0)
+}
+
+func TestDistanceAntipodal(t *testing.T) {
+	assertAlmostEqual(t, math.Pi*EarthRadius,
+		Distance(NewPoint(0, 0), NewPoint(0, 180)), 1)
+}
+
+func TestBearing(t *testing.T) {
+	assertAlmostEqual(t, 330.02, Bearing(paris, london), 0.01)
+	assertAlmostEqual(t, 0, Bearing(NewPoint(0, 0), NewPoint(10, 0)), 1e-9)
+	assertAlmostEqual(t, 90, Bearing(NewPoint(0, 0), NewPoint(0, 10)), 1e-9)
+	assertAlmostEqual(t, 180, Bearing(NewPoint(10, 0), NewPoint(0, 0)), 1e-9)
+	assertAlmostEqual(t, 270, Bearing(NewPoint(0, 10), NewPoint(0, 0)), 1e-9)
+}
+
+func TestDestination(t *testing.T) {
+	var got = Destination(paris, Bearing(paris, london), Distance(paris, london))
+
+	assertAlmostEqual(t, london.Lat(), got.Lat(), 1e-6)
+	assertAlmostEqual(t, london.Lon(), got.Lon(), 1e-6)
+}
+
+func TestDestinationAntimeridian(t *testing.T) {
+	var got = Destination(NewPoint(0, 179), 90, Distance(NewPoint(0, 0), NewPoint(0, 2)))
+
+	assertAlmostEqual(t, 0, got.Lat(), 1e-9)
+	assertAlmostEqual(t, -179, got.Lon(), 1e-9)
+}
+
+func TestPolygonContains(t *testing.T) {
+	var polygon = NewPolygon(
+		NewPoint(0, 0),
+		NewPoint(0, 10),
+		NewPoint(10, 10),
+		NewPoint(10, 0))
+
+	polygon.AddHole(
+		NewPoint(4, 4),
+		NewPoint(4, 6),
+		NewPoint(6, 6),
+		NewPoint(6, 4),
+		NewPoint(4, 4))
+
+	var cases = []struct {
+		point Point
+		want  bool
+	}{
+		{NewPoint(2, 2), true},
+		{NewPoint(8, 5), true},
+		{NewPoint(5, 5), false},
+		{NewPoint(-1, 5), false},
+		{NewPoint(5, 11), false},
+	}
+
+	for _, c := range cases {
+		if got := polygon.Contains(c.point); got != c.want {
+			t.Errorf("Expected Contains(%v) = %v, got %v instead",
+				c.point, c.want, got)
+		}
+	}
+}
+
+func TestPolygonContainsEmpty(t *testing.T) {
+	if (Polygon{}).Contains(NewPoint(0, 0)) {
+		t.Errorf("Expected empty polygon to contain no points")
+	}
+}
+
+func TestPolygonArea(t *testing.T) {
+	var square = NewPolygon(
+		NewPoint(0, 0),
+		NewPoint(0, 1),
+		NewPoint(1, 1),
+		NewPoint(1, 0),
+		NewPoint(0, 0))
+
+	var want = EarthRadius * EarthRadius * radians(1) * math.Sin(radians(1))
+
+	assertAlmostEqual(t, want, square.Area(), 1)
+
+	square.AddHole(
+		NewPoint(0.25, 0.25),
+		NewPoint(0.75, 0.25),
+		NewPoint(0.75, 0.75),
+		NewPoint(0.25, 0.75))
+
+	var hole = EarthRadius * EarthRadius * radians(0.5) *
+		(math.Sin(radians(0.75)) - math.Sin(radians(0.25)))
+
+	assertAlmostEqual(t, want-hole, square.Area(), 1)
+}
+
+func TestPolygonAreaEmpty(t *testing.T) {
+	assertAlmostEqual(t, 0, (Polygon{}).Area(), 0)
+	assertAlmostEqual(t, 0, NewPolygon(NewPoint(0, 0), NewPoint(1, 1)).Area(), 0)
+}
+
+func TestBoundingBoxContains(t *testing.T) {
+	var box = NewBoundingBox(NewPoint(20, 0), NewPoint(0, 20))
+
+	if !box.Contains(NewPoint(10, 10)) {
+		t.Errorf("Expected point to be inside the bounding box")
+	}
+
+	if box.Contains(NewPoint(30, 10)) || box.Contains(NewPoint(10, 30)) {
+		t.Errorf("Expected point to be outside of the bounding box")
+	}
+
+	if (BoundingBox{}).Contains(NewPoint(0, 0)) {
+		t.Errorf("Expected empty bounding box to contain no points")
+	}
+}
+
+func TestBoundingBoxContainsAntimeridian(t *testing.T) {
+	var box = NewBoundingBox(NewPoint(10, 170), NewPoint(-10, -170))
+
+	if !box.Contains(NewPoint(0, 175)) || !box.Contains(NewPoint(0, -175)) {
+		t.Errorf("Expected point to be inside the bounding box")
+	}
+
+	if box.Contains(NewPoint(0, 0)) {
+		t.Errorf("Expected point to be outside of the bounding box")
+	}
+}
+
+func TestBounds(t *testing.T) {
+	var gc = NewGeometryCollection(
+		NewPoint(-5, 3),
+		NewLine(NewPoint(10, -20), NewPoint(2, 2)),
+		NewMultiPolygon(NewPolygon(NewPoint(0, 0), NewPoint(40, 7), NewPoint(1, 1))))
+
+	var got, err = Bounds(gc)
+
+	if err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	var want = NewBoundingBox(NewPoint(40, -20), NewPoint(-5, 7))
+
+	if got.Type != want.Type ||
+		got.Coordinates[0] != want.Coordinates[0] ||
+		got.Coordinates[1] != want.Coordinates[1] {
+		t.Errorf("Expected bounds %v, got %v instead", want, got)
+	}
+}
+
+func TestBoundsCircle(t *testing.T) {
+	var got, err = Bounds(NewCircleLength(NewPoint(0, 0), NewLength(1, NauticalMiles)))
+
+	if err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	// a nautical mile is about one minute of arc
+	assertAlmostEqual(t, 1.0/60, got.Coordinates[0].Lat(), 1e-4)
+	assertAlmostEqual(t, -1.0/60, got.Coordinates[0].Lon(), 1e-4)
+	assertAlmostEqual(t, -1.0/60, got.Coordinates[1].Lat(), 1e-4)
+	assertAlmostEqual(t, 1.0/60, got.Coordinates[1].Lon(), 1e-4)
+}
+
+func TestBoundsCircleHighLatitude(t *testing.T) {
+	var center = NewPoint(60, 10)
+	var r = 100000 / EarthRadius
+	var got, err = Bounds(NewCircleLength(center, NewLength(100, Kilometers)))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the meridian is tangent to the circle north of its east
+	var widest = degrees(math.Acos(math.Tan(r) * math.Tan(radians(center.Lat()))))
+	var east = Destination(center, widest, 100000)
+
+	assertAlmostEqual(t, east.Lon(), got.Coordinates[1].Lon(), 1e-9)
+
+	if east.Lon() <= Destination(center, 90, 100000).Lon() {
+		t.Errorf("Expected widest longitude %v to be east of the bearing 90", east)
+	}
+
+	var upperLeft, lowerRight = got.Coordinates[0], got.Coordinates[1]
+
+	for bearing := 0.0; bearing < 360; bearing += 5 {
+		var p = Destination(center, bearing, 100000)
+
+		if p.Lat() > upperLeft.Lat()+1e-9 || p.Lat() < lowerRight.Lat()-1e-9 ||
+			p.Lon() < upperLeft.Lon()-1e-9 || p.Lon() > lowerRight.Lon()+1e-9 {
+			t.Errorf("Expected bounds %v to contain %v at bearing %v", got, p, bearing)
+		}
+	}
+}
+
+func TestBoundsCircleAntimeridian(t *testing.T) {
+	var got, err = Bounds(NewCircleLength(NewPoint(50, 179.5), NewLength(100, Kilometers)))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var upperLeft, lowerRight = got.Coordinates[0], got.Coordinates[1]
+
+	if upperLeft.Lon() < 178 || lowerRight.Lon() > -178 || upperLeft.Lon() <= lowerRight.Lon() {
+		t.Errorf("Expected bounds crossing the antimeridian, got %v instead", got)
+	}
+
+	if !got.Contains(NewPoint(50, -179.5)) || got.Contains(NewPoint(50, 0)) {
+		t.Errorf("Unexpected bounds %v", got)
+	}
+
+	gc, err := Bounds(NewGeometryCollection(NewCircleLength(NewPoint(50, 179.5), NewLength(100, Kilometers))))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if gc.Coordinates[0].Lon() != -180 || gc.Coordinates[1].Lon() != 180 {
+		t.Errorf("Expected collection bounds to have all longitudes, got %v instead", gc)
+	}
+}
+
+func TestBoundsCirclePole(t *testing.T) {
+	var got, err = Bounds(NewCircleLength(NewPoint(89.5, 0), NewLength(100, Kilometers)))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var want = NewBoundingBox(NewPoint(90, -180), NewPoint(89.5-degrees(100000/EarthRadius), 180))
+
+	if got.Coordinates[0] != want.Coordinates[0] || got.Coordinates[1].Lon() != 180 {
+		t.Errorf("Expected bounds %v, got %v instead", want, got)
+	}
+
+	assertAlmostEqual(t, want.Coordinates[1].Lat(), got.Coordinates[1].Lat(), 1e-9)
+}
+
+func TestBoundsCircleInvalidRadius(t *testing.T) {
+	if _, err := Bounds(NewCircle(NewPoint(0, 0), "2 leagues")); err == nil {
+		t.Errorf("Expected error, got nil instead")
+	}
+}
+
+func TestBoundsEmpty(t *testing.T) {
+	if _, err := Bounds(NewLine()); err != ErrEmptyGeometry {
+		t.Errorf("Expected error %v, got %v instead", ErrEmptyGeometry, err)
+	}
+}
+
+func assertAlmostEqual(t *testing.T, want, got, tolerance float64) {
+	if math.Abs(want-got) > tolerance {
+		t.Errorf("Expected %v (±%v), got %v instead", want, tolerance, got)
+	}
+}
@@ -0,0 +1,286 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+var (
+	// ErrLatitudeOutOfRange is used when a latitude is not in [-90, 90]
+	ErrLatitudeOutOfRange = errors.New("geo: latitude out of [-90, 90] range")
+
+	// ErrLongitudeOutOfRange is used when a longitude is not in [-180, 180]
+	ErrLongitudeOutOfRange = errors.New("geo: longitude out of [-180, 180] range")
+
+	// ErrSwappedCoordinates is used when the latitude is out of range
+	// but the point would be valid with latitude and longitude swapped
+	ErrSwappedCoordinates = errors.New("geo: latitude and longitude are likely swapped")
+
+	// ErrRingNotClosed is used when the first and last points of a ring differ
+	ErrRingNotClosed = errors.New("geo: polygon ring is not closed")
+
+	// ErrRingTooShort is used when a ring has less than 4 positions
+	ErrRingTooShort = errors.New("geo: polygon ring must have at least 4 positions")
+
+	// ErrWindingOrder is used by ValidateWinding when the exterior ring
+	// isn't counterclockwise or a hole isn't clockwise, as RFC 7946
+	// recommends
+	ErrWindingOrder = errors.New("geo: polygon ring has wrong winding order")
+)
+
+// NewValidPoint creates a new point, validating the coordinates
+func NewValidPoint(lat, lon float64) (Point, error) {
+	var p = NewPoint(lat, lon)
+	return p, p.Validate()
+}
+
+// Validate the coordinates of the point
+func (p Point) Validate() error {
+	switch {
+	case !validLat(p.Lat()) && validLat(p.Lon()) && validLon(p.Lat()):
+		return ErrSwappedCoordinates
+	case !validLat(p.Lat()):
+		return ErrLatitudeOutOfRange
+	case !validLon(p.Lon()):
+		return ErrLongitudeOutOfRange
+	}
+
+	return nil
+}
+
+// LikelySwapped checks if the point is only valid with latitude and longitude swapped
+func (p Point) LikelySwapped() bool {
+	return p.Validate() == ErrSwappedCoordinates
+}
+
+// Swap latitude and longitude
+func (p Point) Swap() Point {
+	return NewPoint(p.Lon(), p.Lat())
+}
+
+// WrapLongitude wraps the longitude into the [-180, 180] interval,
+// so that 190 becomes -170
+func (p Point) WrapLongitude() Point {
+	var lon = p.Lon()
+
+	if validLon(lon) || math.IsNaN(lon) || math.IsInf(lon, 0) {
+		return p
+	}
+
+	return NewPoint(p.Lat(), math.Mod(math.Mod(lon+180, 360)+360, 360)-180)
+}
+
+// Validate the bounding box
+func (b BoundingBox) Validate() error {
+	if len(b.Coordinates) != 2 {
+		return fmt.Errorf("geo: bounding box must have 2 points, got %d",
+			len(b.Coordinates))
+	}
+
+	if err := validatePoints(b.Coordinates); err != nil {
+		return err
+	}
+
+	if b.Coordinates[0].Lat() < b.Coordinates[1].Lat() {
+		return errors.New("geo: bounding box upper left is below its lower right")
+	}
+
+	return nil
+}
+
+// Validate the circle center and radius
+func (c Circle) Validate() error {
+	if err := c.Coordinates.Validate(); err != nil {
+		return err
+	}
+
+	var _, err = c.RadiusLength()
+	return err
+}
+
+// Validate the geometries of the collection
+func (gc GeometryCollection) Validate() error {
+	for _, g := range gc.Geometries {
+		if err := g.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Validate the line
+func (l Line) Validate() error {
+	return validateLine(l.Coordinates)
+}
+
+// Validate the lines
+func (ml MultiLine) Validate() error {
+	for _, l := range ml.Coordinates {
+		if err := validateLine(l); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Validate the points
+func (mp MultiPoint) Validate() error {
+	return validatePoints(mp.Coordinates)
+}
+
+// Validate the polygons
+func (mp MultiPolygon) Validate() error {
+	for _, rings := range mp.Coordinates {
+		if err := validateRings(rings); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ValidateWinding checks the winding order of the rings of the polygons
+func (mp MultiPolygon) ValidateWinding() error {
+	for _, rings := range mp.Coordinates {
+		if err := validateWinding(rings); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Validate the polygon rings: coordinates and closure.
+// Rings with any winding order are valid, as RFC 7946 requires
+// (see ValidateWinding and Normalize).
+func (p Polygon) Validate() error {
+	return validateRings(p.Coordinates)
+}
+
+// ValidateWinding checks if the exterior ring is counterclockwise
+// and the holes are clockwise, following the right-hand rule
+func (p Polygon) ValidateWinding() error {
+	return validateWinding(p.Coordinates)
+}
+
+// Closed checks if all rings of the polygon are closed
+func (p Polygon) Closed() bool {
+	for _, ring := range p.Coordinates {
+		if !ringClosed(ring) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Normalize returns a copy of the polygon with closed rings
+// and the exterior ring counterclockwise and holes clockwise
+func (p Polygon) Normalize() Polygon {
+	var n = Polygon{
+		Type:        p.Type,
+		Coordinates: [][]Point{},
+	}
+
+	for i, ring := range p.Coordinates {
+		var r = append([]Point{}, ring...)
+
+		if len(r) != 0 && !ringClosed(r) {
+			r = append(r, r[0])
+		}
+
+		if (i == 0) != counterclockwise(r) {
+			reverse(r)
+		}
+
+		n.Coordinates = append(n.Coordinates, r)
+	}
+
+	return n
+}
+
+func validateLine(points []Point) error {
+	if len(points) < 2 {
+		return errors.New("geo: line must have at least 2 points")
+	}
+
+	return validatePoints(points)
+}
+
+func validatePoints(points []Point) error {
+	for _, p := range points {
+		if err := p.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateRings(rings [][]Point) error {
+	if len(rings) == 0 {
+		return ErrEmptyGeometry
+	}
+
+	for _, ring := range rings {
+		if err := validatePoints(ring); err != nil {
+			return err
+		}
+
+		if len(ring) < 4 {
+			return ErrRingTooShort
+		}
+
+		if !ringClosed(ring) {
+			return ErrRingNotClosed
+		}
+	}
+
+	return nil
+}
+
+func validateWinding(rings [][]Point) error {
+	for i, ring := range rings {
+		if (i == 0) != counterclockwise(ring) {
+			return ErrWindingOrder
+		}
+	}
+
+	return nil
+}
+
+func ringClosed(ring []Point) bool {
+	return len(ring) != 0 && ring[0] == ring[len(ring)-1]
+}
+
+// counterclockwise uses the signed area of the ring on the longitude/latitude plane
+func counterclockwise(ring []Point) bool {
+	var sum float64
+
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		sum += (ring[j].Lon() - ring[i].Lon()) * (ring[j].Lat() + ring[i].Lat())
+	}
+
+	return sum > 0
+}
+
+func reverse(points []Point) {
+	for i, j := 0, len(points)-1; i < j; i, j = i+1, j-1 {
+		points[i], points[j] = points[j], points[i]
+	}
+}
+
+func validLat(lat float64) bool {
+	return lat >= -90 && lat <= 90
+}
+
+func validLon(lon float64) bool {
+	return lon >= -180 && lon <= 180
+}
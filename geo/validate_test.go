@@ -0,0 +1,222 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geo
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestPointValidate(t *testing.T) {
+	var cases = []struct {
+		point Point
+		want  error
+	}{
+		{NewPoint(0, 0), nil},
+		{NewPoint(90, 180), nil},
+		{NewPoint(-90, -180), nil},
+		{NewPoint(91, 100), ErrLatitudeOutOfRange},
+		{NewPoint(91, 0), ErrSwappedCoordinates},
+		{NewPoint(-91, 181), ErrLatitudeOutOfRange},
+		{NewPoint(0, 181), ErrLongitudeOutOfRange},
+		{NewPoint(-23.5, -190), ErrLongitudeOutOfRange},
+		{NewPoint(-46.6, -23.5), nil},
+		{NewPoint(120, 45), ErrSwappedCoordinates},
+		{NewPoint(math.NaN(), 0), ErrLatitudeOutOfRange},
+		{NewPoint(0, math.Inf(1)), ErrLongitudeOutOfRange},
+	}
+
+	for _, c := range cases {
+		if got := c.point.Validate(); got != c.want {
+			t.Errorf("Expected %v to validate as %v, got %v instead",
+				c.point, c.want, got)
+		}
+	}
+}
+
+func TestNewValidPoint(t *testing.T) {
+	if _, err := NewValidPoint(10, 20); err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	if _, err := NewValidPoint(100, 20); err != ErrSwappedCoordinates {
+		t.Errorf("Expected error %v, got %v instead", ErrSwappedCoordinates, err)
+	}
+}
+
+func TestPointLikelySwapped(t *testing.T) {
+	var p = NewPoint(120, 45)
+
+	if !p.LikelySwapped() {
+		t.Errorf("Expected %v to be likely swapped", p)
+	}
+
+	if err := p.Swap().Validate(); err != nil {
+		t.Errorf("Expected swapped point to be valid, got %v instead", err)
+	}
+
+	if NewPoint(45, 120).LikelySwapped() {
+		t.Errorf("Expected valid point not to be likely swapped")
+	}
+}
+
+func TestPointWrapLongitude(t *testing.T) {
+	var cases = []struct {
+		lon  float64
+		want float64
+	}{
+		{0, 0},
+		{180, 180},
+		{-180, -180},
+		{190, -170},
+		{-190, 170},
+		{540, -180},
+		{725, 5},
+	}
+
+	for _, c := range cases {
+		var got = NewPoint(10, c.lon).WrapLongitude()
+
+		if got.Lat() != 10 || math.Abs(got.Lon()-c.want) > 1e-9 {
+			t.Errorf("Expected longitude %v to wrap to %v, got %v instead",
+				c.lon, c.want, got.Lon())
+		}
+	}
+}
+
+func TestPolygonValidate(t *testing.T) {
+	var ccw = []Point{
+		NewPoint(0, 0), NewPoint(0, 10), NewPoint(10, 10), NewPoint(10, 0), NewPoint(0, 0),
+	}
+
+	var cw = []Point{
+		NewPoint(2, 2), NewPoint(4, 2), NewPoint(4, 4), NewPoint(2, 4), NewPoint(2, 2),
+	}
+
+	var cases = []struct {
+		polygon Polygon
+		want    error
+	}{
+		{Polygon{Coordinates: [][]Point{ccw, cw}}, nil},
+		{Polygon{Coordinates: [][]Point{cw}}, nil},
+		{Polygon{Coordinates: [][]Point{ccw, ccw}}, nil},
+		{NewPolygon(NewPoint(0, 0), NewPoint(0, 30), NewPoint(40, 0)), ErrRingTooShort},
+		{NewPolygon(ccw[:4]...), ErrRingNotClosed},
+		{NewPolygon(NewPoint(0, 0), NewPoint(0, 200), NewPoint(1, 1), NewPoint(0, 0)),
+			ErrLongitudeOutOfRange},
+		{Polygon{}, ErrEmptyGeometry},
+	}
+
+	for _, c := range cases {
+		if got := c.polygon.Validate(); got != c.want {
+			t.Errorf("Expected %v to validate as %v, got %v instead",
+				c.polygon, c.want, got)
+		}
+	}
+}
+
+func TestPolygonValidateWinding(t *testing.T) {
+	var ccw = []Point{
+		NewPoint(0, 0), NewPoint(0, 10), NewPoint(10, 10), NewPoint(10, 0), NewPoint(0, 0),
+	}
+
+	var cw = []Point{
+		NewPoint(2, 2), NewPoint(4, 2), NewPoint(4, 4), NewPoint(2, 4), NewPoint(2, 2),
+	}
+
+	var cases = []struct {
+		polygon Polygon
+		want    error
+	}{
+		{Polygon{Coordinates: [][]Point{ccw, cw}}, nil},
+		{Polygon{Coordinates: [][]Point{cw}}, ErrWindingOrder},
+		{Polygon{Coordinates: [][]Point{ccw, ccw}}, ErrWindingOrder},
+	}
+
+	for _, c := range cases {
+		if got := c.polygon.ValidateWinding(); got != c.want {
+			t.Errorf("Expected %v winding to validate as %v, got %v instead",
+				c.polygon, c.want, got)
+		}
+	}
+
+	var mp = MultiPolygon{Coordinates: [][][]Point{{ccw}, {cw}}}
+
+	if err := mp.ValidateWinding(); err != ErrWindingOrder {
+		t.Errorf("Expected error %v, got %v instead", ErrWindingOrder, err)
+	}
+}
+
+func TestPolygonNormalize(t *testing.T) {
+	var polygon = NewPolygon(NewPoint(0, 0), NewPoint(10, 0), NewPoint(10, 10), NewPoint(0, 10))
+	polygon.AddHole(NewPoint(2, 2), NewPoint(2, 4), NewPoint(4, 4), NewPoint(4, 2))
+
+	if polygon.Closed() {
+		t.Errorf("Expected polygon not to be closed")
+	}
+
+	var got = polygon.Normalize()
+
+	if err := got.Validate(); err != nil {
+		t.Errorf("Expected normalized polygon to be valid, got %v instead", err)
+	}
+
+	if err := got.ValidateWinding(); err != nil {
+		t.Errorf("Expected normalized polygon to follow the right-hand rule, got %v instead", err)
+	}
+
+	if !got.Closed() {
+		t.Errorf("Expected normalized polygon to be closed")
+	}
+
+	var want = [][]Point{
+		{NewPoint(0, 0), NewPoint(0, 10), NewPoint(10, 10), NewPoint(10, 0), NewPoint(0, 0)},
+		{NewPoint(2, 2), NewPoint(4, 2), NewPoint(4, 4), NewPoint(2, 4), NewPoint(2, 2)},
+	}
+
+	if !reflect.DeepEqual(got.Coordinates, want) {
+		t.Errorf("Expected %v, got %v instead", want, got.Coordinates)
+	}
+
+	if polygon.Coordinates[0][1] != NewPoint(10, 0) {
+		t.Errorf("Expected original polygon not to be changed")
+	}
+}
+
+func TestGeometriesValidate(t *testing.T) {
+	var valid = []Geometry{
+		NewBoundingBox(NewPoint(20, 0), NewPoint(0, 20)),
+		NewCircle(NewPoint(0, 0), "2km"),
+		NewLine(NewPoint(0, 0), NewPoint(1, 1)),
+		NewMultiLine(NewLine(NewPoint(0, 0), NewPoint(1, 1))),
+		NewMultiPoint(NewPoint(0, 0)),
+		NewGeometryCollection(NewPoint(0, 0)),
+	}
+
+	for _, g := range valid {
+		if err := g.Validate(); err != nil {
+			t.Errorf("Expected %v to be valid, got %v instead", g, err)
+		}
+	}
+
+	var invalid = []Geometry{
+		NewBoundingBox(NewPoint(0, 0), NewPoint(20, 20)),
+		BoundingBox{},
+		NewCircle(NewPoint(0, 0), "2 parsecs"),
+		NewCircle(NewPoint(100, 0), "2km"),
+		NewLine(NewPoint(0, 0)),
+		NewMultiLine(NewLine(NewPoint(0, 0), NewPoint(1, 181))),
+		NewMultiPoint(NewPoint(-91, 0)),
+		NewMultiPolygon(NewPolygon(NewPoint(0, 0))),
+		NewGeometryCollection(NewPoint(0, 0), NewPoint(0, 200)),
+	}
+
+	for _, g := range invalid {
+		if err := g.Validate(); err == nil {
+			t.Errorf("Expected %v to be invalid", g)
+		}
+	}
+}
@@ -0,0 +1,460 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geo
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// WKT encodes the point as Well-Known Text
+func (p Point) WKT() string {
+	return "POINT (" + wktPosition(p) + ")"
+}
+
+// WKT encodes the line as Well-Known Text
+func (l Line) WKT() string {
+	return "LINESTRING " + wktPositions(l.Coordinates)
+}
+
+// WKT encodes the polygon as Well-Known Text
+func (p Polygon) WKT() string {
+	return "POLYGON " + wktRings(p.Coordinates)
+}
+
+// WKT encodes the multi point as Well-Known Text
+func (mp MultiPoint) WKT() string {
+	if len(mp.Coordinates) == 0 {
+		return "MULTIPOINT EMPTY"
+	}
+
+	var points []string
+
+	for _, p := range mp.Coordinates {
+		points = append(points, "("+wktPosition(p)+")")
+	}
+
+	return "MULTIPOINT (" + strings.Join(points, ", ") + ")"
+}
+
+// WKT encodes the multi line as Well-Known Text
+func (ml MultiLine) WKT() string {
+	return "MULTILINESTRING " + wktRings(ml.Coordinates)
+}
+
+// WKT encodes the multi polygon as Well-Known Text
+func (mp MultiPolygon) WKT() string {
+	if len(mp.Coordinates) == 0 {
+		return "MULTIPOLYGON EMPTY"
+	}
+
+	var polygons []string
+
+	for _, p := range mp.Coordinates {
+		polygons = append(polygons, wktRings(p))
+	}
+
+	return "MULTIPOLYGON (" + strings.Join(polygons, ", ") + ")"
+}
+
+// WKT encodes the geometry collection as Well-Known Text.
+// Circles are skipped, as they have no Well-Known Text representation.
+func (gc GeometryCollection) WKT() string {
+	var geometries []string
+
+	for _, g := range gc.Geometries {
+		if w, ok := g.(interface {
+			WKT() string
+		}); ok {
+			geometries = append(geometries, w.WKT())
+		}
+	}
+
+	if len(geometries) == 0 {
+		return "GEOMETRYCOLLECTION EMPTY"
+	}
+
+	return "GEOMETRYCOLLECTION (" + strings.Join(geometries, ", ") + ")"
+}
+
+// WKT encodes the bounding box as Well-Known Text, using the
+// BBOX (minLon, maxLon, maxLat, minLat) extension used by Elasticsearch
+func (b BoundingBox) WKT() string {
+	if len(b.Coordinates) != 2 {
+		return "BBOX EMPTY"
+	}
+
+	var upperLeft, lowerRight = b.Coordinates[0], b.Coordinates[1]
+
+	return fmt.Sprintf("BBOX (%s, %s, %s, %s)",
+		wktNumber(upperLeft.Lon()),
+		wktNumber(lowerRight.Lon()),
+		wktNumber(upperLeft.Lat()),
+		wktNumber(lowerRight.Lat()))
+}
+
+// ParseWKT decodes a geometry from Well-Known Text.
+// Z and M coordinates are discarded and BBOX or ENVELOPE are decoded
+// as a BoundingBox. Circles have no Well-Known Text representation.
+func ParseWKT(s string) (Geometry, error) {
+	var p = &wktParser{
+		tokens: wktTokenize(s),
+	}
+
+	var g, err = p.geometry()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if p.pos != len(p.tokens) {
+		return nil, p.errorf("unexpected %q", p.tokens[p.pos])
+	}
+
+	return g, nil
+}
+
+func wktNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func wktPosition(p Point) string {
+	return wktNumber(p.Lon()) + " " + wktNumber(p.Lat())
+}
+
+func wktPositions(points []Point) string {
+	if len(points) == 0 {
+		return "EMPTY"
+	}
+
+	var positions []string
+
+	for _, p := range points {
+		positions = append(positions, wktPosition(p))
+	}
+
+	return "(" + strings.Join(positions, ", ") + ")"
+}
+
+func wktRings(rings [][]Point) string {
+	if len(rings) == 0 {
+		return "EMPTY"
+	}
+
+	var list []string
+
+	for _, r := range rings {
+		list = append(list, wktPositions(r))
+	}
+
+	return "(" + strings.Join(list, ", ") + ")"
+}
+
+func wktTokenize(s string) []string {
+	var tokens []string
+	var token bytes.Buffer
+
+	var flush = func() {
+		if token.Len() != 0 {
+			tokens = append(tokens, token.String())
+			token.Reset()
+		}
+	}
+
+	for _, r := range s {
+		switch {
+		case unicode.IsSpace(r):
+			flush()
+		case r == '(' || r == ')' || r == ',':
+			flush()
+			tokens = append(tokens, string(r))
+		default:
+			token.WriteRune(r)
+		}
+	}
+
+	flush()
+	return tokens
+}
+
+type wktParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *wktParser) errorf(format string, a ...interface{}) error {
+	return fmt.Errorf("geo: invalid WKT: "+format, a...)
+}
+
+func (p *wktParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return strings.ToUpper(p.tokens[p.pos])
+	}
+
+	return ""
+}
+
+func (p *wktParser) next() string {
+	var t = p.peek()
+
+	if p.pos < len(p.tokens) {
+		p.pos++
+	}
+
+	return t
+}
+
+func (p *wktParser) expect(token string) error {
+	if t := p.next(); t != token {
+		if t == "" {
+			return p.errorf("expected %q, got end of text", token)
+		}
+
+		return p.errorf("expected %q, got %q", token, t)
+	}
+
+	return nil
+}
+
+func (p *wktParser) empty() bool {
+	switch p.peek() {
+	case "Z", "M", "ZM":
+		p.next()
+	}
+
+	if p.peek() == "EMPTY" {
+		p.next()
+		return true
+	}
+
+	return false
+}
+
+func (p *wktParser) geometry() (Geometry, error) {
+	var tag = p.next()
+	var empty = p.empty()
+
+	switch tag {
+	case "POINT":
+		if empty {
+			return nil, p.errorf("empty point")
+		}
+
+		return p.point()
+	case "LINESTRING":
+		var points, err = p.positions(empty)
+		return NewLine(points...), err
+	case "POLYGON":
+		var rings, err = p.rings(empty)
+		return Polygon{Type: "polygon", Coordinates: rings}, err
+	case "MULTIPOINT":
+		var points, err = p.multiPoint(empty)
+		return NewMultiPoint(points...), err
+	case "MULTILINESTRING":
+		var lines, err = p.rings(empty)
+		return MultiLine{Type: "multilinestring", Coordinates: lines}, err
+	case "MULTIPOLYGON":
+		return p.multiPolygon(empty)
+	case "GEOMETRYCOLLECTION":
+		return p.geometryCollection(empty)
+	case "BBOX", "ENVELOPE":
+		if empty {
+			return nil, p.errorf("empty bounding box")
+		}
+
+		return p.boundingBox()
+	case "":
+		return nil, p.errorf("empty text")
+	}
+
+	return nil, p.errorf("unknown geometry type %q", tag)
+}
+
+func (p *wktParser) number() (float64, error) {
+	var t = p.next()
+	var f, err = strconv.ParseFloat(t, 64)
+
+	if err != nil {
+		return 0, p.errorf("expected number, got %q", t)
+	}
+
+	return f, nil
+}
+
+func (p *wktParser) position() (Point, error) {
+	var coords []float64
+
+	for len(coords) < 4 {
+		if _, err := strconv.ParseFloat(p.peek(), 64); err != nil {
+			break
+		}
+
+		var f, _ = p.number()
+		coords = append(coords, f)
+	}
+
+	if len(coords) < 2 {
+		return Point{}, p.errorf("position must have 2 or more coordinates")
+	}
+
+	return NewPoint(coords[1], coords[0]), nil
+}
+
+func (p *wktParser) point() (Point, error) {
+	if err := p.expect("("); err != nil {
+		return Point{}, err
+	}
+
+	var point, err = p.position()
+
+	if err != nil {
+		return Point{}, err
+	}
+
+	return point, p.expect(")")
+}
+
+// list parses a parenthesized, comma-separated list of items
+func (p *wktParser) list(item func() error) error {
+	if err := p.expect("("); err != nil {
+		return err
+	}
+
+	for {
+		if err := item(); err != nil {
+			return err
+		}
+
+		if p.peek() != "," {
+			break
+		}
+
+		p.next()
+	}
+
+	return p.expect(")")
+}
+
+func (p *wktParser) positions(empty bool) ([]Point, error) {
+	var points = []Point{}
+
+	if empty {
+		return points, nil
+	}
+
+	var err = p.list(func() error {
+		var point, err = p.position()
+		points = append(points, point)
+		return err
+	})
+
+	return points, err
+}
+
+func (p *wktParser) rings(empty bool) ([][]Point, error) {
+	var rings = [][]Point{}
+
+	if empty {
+		return rings, nil
+	}
+
+	var err = p.list(func() error {
+		var points, err = p.positions(p.empty())
+		rings = append(rings, points)
+		return err
+	})
+
+	return rings, err
+}
+
+func (p *wktParser) multiPoint(empty bool) ([]Point, error) {
+	var points = []Point{}
+
+	if empty {
+		return points, nil
+	}
+
+	var err = p.list(func() error {
+		var point Point
+		var err error
+
+		if p.peek() == "(" {
+			point, err = p.point()
+		} else {
+			point, err = p.position()
+		}
+
+		points = append(points, point)
+		return err
+	})
+
+	return points, err
+}
+
+func (p *wktParser) multiPolygon(empty bool) (Geometry, error) {
+	var mp = MultiPolygon{
+		Type:        "multipolygon",
+		Coordinates: [][][]Point{},
+	}
+
+	if empty {
+		return mp, nil
+	}
+
+	var err = p.list(func() error {
+		var rings, err = p.rings(p.empty())
+		mp.Coordinates = append(mp.Coordinates, rings)
+		return err
+	})
+
+	return mp, err
+}
+
+func (p *wktParser) geometryCollection(empty bool) (Geometry, error) {
+	var gc = NewGeometryCollection()
+	gc.Geometries = []Geometry{}
+
+	if empty {
+		return gc, nil
+	}
+
+	var err = p.list(func() error {
+		var g, err = p.geometry()
+		gc.Geometries = append(gc.Geometries, g)
+		return err
+	})
+
+	return gc, err
+}
+
+func (p *wktParser) boundingBox() (Geometry, error) {
+	var n [4]float64
+
+	var i = 0
+	var err = p.list(func() error {
+		if i == len(n) {
+			return p.errorf("bounding box must have 4 values")
+		}
+
+		var err error
+		n[i], err = p.number()
+		i++
+		return err
+	})
+
+	if err == nil && i != len(n) {
+		err = p.errorf("bounding box must have 4 values")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var minLon, maxLon, maxLat, minLat = n[0], n[1], n[2], n[3]
+	return NewBoundingBox(NewPoint(maxLat, minLon), NewPoint(minLat, maxLon)), nil
+}
@@ -0,0 +1,122 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWKT(t *testing.T) {
+	var polygon = NewPolygon(
+		NewPoint(0, 0), NewPoint(0, 10), NewPoint(10, 10), NewPoint(0, 0))
+	polygon.AddHole(
+		NewPoint(1, 1), NewPoint(2, 1), NewPoint(2, 2), NewPoint(1, 1))
+
+	var cases = []struct {
+		geometry interface {
+			WKT() string
+		}
+		want string
+	}{
+		{NewPoint(-23.5, -46.625), "POINT (-46.625 -23.5)"},
+		{NewLine(NewPoint(10, 20), NewPoint(10, 30)), "LINESTRING (20 10, 30 10)"},
+		{NewLine(), "LINESTRING EMPTY"},
+		{polygon, "POLYGON ((0 0, 10 0, 10 10, 0 0), (1 1, 1 2, 2 2, 1 1))"},
+		{Polygon{}, "POLYGON EMPTY"},
+		{NewMultiPoint(NewPoint(1, 2), NewPoint(3, 4)), "MULTIPOINT ((2 1), (4 3))"},
+		{NewMultiPoint(), "MULTIPOINT EMPTY"},
+		{NewMultiLine(
+			NewLine(NewPoint(1, 2), NewPoint(3, 4)),
+			NewLine(NewPoint(5, 6), NewPoint(7, 8))),
+			"MULTILINESTRING ((2 1, 4 3), (6 5, 8 7))"},
+		{NewMultiPolygon(polygon),
+			"MULTIPOLYGON (((0 0, 10 0, 10 10, 0 0), (1 1, 1 2, 2 2, 1 1)))"},
+		{NewMultiPolygon(), "MULTIPOLYGON EMPTY"},
+		{NewGeometryCollection(
+			NewPoint(1, 2),
+			NewCircle(NewPoint(0, 0), "1km"),
+			NewLine(NewPoint(1, 2), NewPoint(3, 4))),
+			"GEOMETRYCOLLECTION (POINT (2 1), LINESTRING (2 1, 4 3))"},
+		{NewGeometryCollection(), "GEOMETRYCOLLECTION EMPTY"},
+		{NewBoundingBox(NewPoint(20, -10), NewPoint(0, 30)), "BBOX (-10, 30, 20, 0)"},
+		{BoundingBox{}, "BBOX EMPTY"},
+	}
+
+	for _, c := range cases {
+		if got := c.geometry.WKT(); got != c.want {
+			t.Errorf("Expected WKT %v, got %v instead", c.want, got)
+		}
+	}
+}
+
+func TestParseWKT(t *testing.T) {
+	var cases = []struct {
+		wkt  string
+		want Geometry
+	}{
+		{"POINT (-46.625 -23.5)", NewPoint(-23.5, -46.625)},
+		{"point z (1 2 3)", NewPoint(2, 1)},
+		{"POINT ZM (1 2 3 4)", NewPoint(2, 1)},
+		{"LINESTRING(20 10,30 10)", NewLine(NewPoint(10, 20), NewPoint(10, 30))},
+		{"LINESTRING EMPTY", Line{Type: "linestring", Coordinates: []Point{}}},
+		{"POLYGON ((0 0, 10 0, 10 10, 0 0))",
+			NewPolygon(NewPoint(0, 0), NewPoint(0, 10), NewPoint(10, 10), NewPoint(0, 0))},
+		{"MULTIPOINT ((2 1), (4 3))", NewMultiPoint(NewPoint(1, 2), NewPoint(3, 4))},
+		{"MULTIPOINT (2 1, 4 3)", NewMultiPoint(NewPoint(1, 2), NewPoint(3, 4))},
+		{"MULTILINESTRING ((2 1, 4 3))",
+			NewMultiLine(NewLine(NewPoint(1, 2), NewPoint(3, 4)))},
+		{"MULTIPOLYGON (((0 0, 1 0, 0 1, 0 0)), EMPTY)",
+			MultiPolygon{Type: "multipolygon", Coordinates: [][][]Point{
+				{{NewPoint(0, 0), NewPoint(0, 1), NewPoint(1, 0), NewPoint(0, 0)}},
+				{},
+			}}},
+		{"GEOMETRYCOLLECTION (POINT (2 1), LINESTRING (2 1, 4 3))",
+			NewGeometryCollection(
+				NewPoint(1, 2),
+				NewLine(NewPoint(1, 2), NewPoint(3, 4)))},
+		{"GEOMETRYCOLLECTION EMPTY",
+			GeometryCollection{Type: "geometrycollection", Geometries: []Geometry{}}},
+		{"BBOX (-10, 30, 20, 0)", NewBoundingBox(NewPoint(20, -10), NewPoint(0, 30))},
+		{"ENVELOPE (-10, 30, 20, 0)", NewBoundingBox(NewPoint(20, -10), NewPoint(0, 30))},
+	}
+
+	for _, c := range cases {
+		var got, err = ParseWKT(c.wkt)
+
+		if err != nil {
+			t.Errorf("Expected no error parsing %v, got %v instead", c.wkt, err)
+		}
+
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("Expected %v to be parsed as %v, got %v instead", c.wkt, c.want, got)
+		}
+	}
+}
+
+func TestParseWKTErrors(t *testing.T) {
+	var cases = []string{
+		"",
+		"CIRCLE (0 0, 10)",
+		"POINT EMPTY",
+		"POINT (1)",
+		"POINT (1 2",
+		"POINT (1 2) POINT (1 2)",
+		"LINESTRING (1 2, x y)",
+		"POLYGON (1 2, 3 4)",
+		"MULTIPOINT ((1 2), (3))",
+		"GEOMETRYCOLLECTION (FOO (1 2))",
+		"BBOX EMPTY",
+		"BBOX (1, 2, 3)",
+		"BBOX (1, 2, 3, 4, 5)",
+		"BBOX (1, 2, 3, x)",
+	}
+
+	for _, c := range cases {
+		if _, err := ParseWKT(c); err == nil {
+			t.Errorf("Expected error parsing %q, got nil instead", c)
+		}
+	}
+}
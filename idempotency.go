@@ -0,0 +1,76 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wedeploy
+
+import (
+	"crypto/rand"
+	"fmt"
+)
+
+// IdempotencyKeyHeader is the header with the idempotency key of a request
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// DefaultIdempotentMethods get idempotency keys when WithIdempotencyKeys
+// is used without methods
+var DefaultIdempotentMethods = []string{"POST", "PATCH"}
+
+// WithIdempotencyKeys attaches an idempotency key to the requests of the
+// client sent with the methods (DefaultIdempotentMethods, if none is given)
+func WithIdempotencyKeys(methods ...string) Option {
+	if len(methods) == 0 {
+		methods = DefaultIdempotentMethods
+	}
+
+	return func(h *HTTPClient) {
+		h.idempotentMethods = map[string]bool{}
+
+		for _, m := range methods {
+			h.idempotentMethods[m] = true
+		}
+	}
+}
+
+// Idempotent attaches an idempotency key to the request, so that the
+// server can recognize it when it is sent again, such as on a retry.
+// The key is generated when the request is first sent, and is reused
+// until IdempotencyKey is cleared. Clones of the request get a new key.
+func (w *WeDeploy) Idempotent() *WeDeploy {
+	w.idempotent = true
+	return w
+}
+
+// setupIdempotencyKey gets the key from the Idempotency-Key header set on
+// the request, or generates it if the request or method is idempotent
+func (w *WeDeploy) setupIdempotencyKey(method string) error {
+	if w.IdempotencyKey == "" {
+		w.IdempotencyKey = w.Headers.Get(IdempotencyKeyHeader)
+	}
+
+	if w.IdempotencyKey == "" && (w.idempotent || w.getClient().idempotentMethods[method]) {
+		var key, err = newIdempotencyKey()
+
+		if err != nil {
+			return err
+		}
+
+		w.IdempotencyKey = key
+	}
+
+	return nil
+}
+
+// newIdempotencyKey creates a random (version 4) UUID
+func newIdempotencyKey() (string, error) {
+	var b [16]byte
+
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
@@ -0,0 +1,167 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wedeploy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func setupIdempotencyServer(keys *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*keys = append(*keys, r.Header.Get(IdempotencyKeyHeader))
+	}))
+}
+
+func TestIdempotent(t *testing.T) {
+	var keys []string
+	var s = setupIdempotencyServer(&keys)
+	defer s.Close()
+
+	var req = URL(s.URL).Idempotent()
+
+	if req.IdempotencyKey != "" {
+		t.Errorf("Expected key to be generated when the request is sent")
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := req.Post(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if !uuidPattern.MatchString(req.IdempotencyKey) {
+		t.Errorf("Expected key to be an UUID, got %v instead", req.IdempotencyKey)
+	}
+
+	if len(keys) != 2 || keys[0] != req.IdempotencyKey || keys[1] != req.IdempotencyKey {
+		t.Errorf("Expected key %v to be reused, got %v instead", req.IdempotencyKey, keys)
+	}
+
+	var c = req.Clone()
+
+	if err := c.Post(); err != nil {
+		t.Fatal(err)
+	}
+
+	if c.IdempotencyKey == "" || c.IdempotencyKey == req.IdempotencyKey {
+		t.Errorf("Expected clone to get a new key, got %v instead", c.IdempotencyKey)
+	}
+
+	req.IdempotencyKey = ""
+
+	if err := req.Post(); err != nil {
+		t.Fatal(err)
+	}
+
+	if req.IdempotencyKey == keys[0] || keys[3] != req.IdempotencyKey {
+		t.Errorf("Expected clearing the key to generate a new one, got %v instead", keys)
+	}
+}
+
+func TestIdempotencyKeyDump(t *testing.T) {
+	var keys []string
+	var s = setupIdempotencyServer(&keys)
+	defer s.Close()
+
+	var client = NewHTTPClient(WithIdempotencyKeys())
+
+	for _, req := range []*WeDeploy{URL(s.URL).Idempotent(), client.URL(s.URL)} {
+		keys = nil
+		var curl, err = req.Curl("POST")
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		dump, err := req.Dump("POST")
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := req.Post(); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(keys) != 1 || !uuidPattern.MatchString(keys[0]) ||
+			!strings.Contains(curl, "-H '"+IdempotencyKeyHeader+": "+keys[0]+"'") ||
+			!strings.Contains(string(dump), IdempotencyKeyHeader+": "+keys[0]+"\r\n") {
+			t.Errorf("Expected dumps to have the key sent %v, got %v and %q instead", keys, curl, dump)
+		}
+	}
+}
+
+func TestIdempotencyKeyExplicit(t *testing.T) {
+	var keys []string
+	var s = setupIdempotencyServer(&keys)
+	defer s.Close()
+
+	var req = URL(s.URL)
+	req.IdempotencyKey = "abc"
+
+	if err := req.Put(); err != nil {
+		t.Fatal(err)
+	}
+
+	var header = URL(s.URL).Idempotent()
+	header.Header(IdempotencyKeyHeader, "def")
+
+	if err := header.Post(); err != nil {
+		t.Fatal(err)
+	}
+
+	if header.IdempotencyKey != "def" {
+		t.Errorf("Expected key from the header, got %v instead", header.IdempotencyKey)
+	}
+
+	if len(keys) != 2 || keys[0] != "abc" || keys[1] != "def" {
+		t.Errorf("Expected explicit keys, got %v instead", keys)
+	}
+}
+
+func TestWithIdempotencyKeys(t *testing.T) {
+	var keys []string
+	var s = setupIdempotencyServer(&keys)
+	defer s.Close()
+
+	var client = NewHTTPClient(WithIdempotencyKeys())
+
+	for _, send := range []func(*WeDeploy) error{
+		(*WeDeploy).Post, (*WeDeploy).Patch, (*WeDeploy).Put, (*WeDeploy).Get,
+	} {
+		if err := send(client.URL(s.URL)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if !uuidPattern.MatchString(keys[0]) || !uuidPattern.MatchString(keys[1]) || keys[0] == keys[1] {
+		t.Errorf("Expected POST and PATCH to get different keys, got %v instead", keys)
+	}
+
+	if keys[2] != "" || keys[3] != "" {
+		t.Errorf("Expected PUT and GET not to get keys, got %v instead", keys)
+	}
+
+	client = NewHTTPClient(WithIdempotencyKeys("PUT"))
+	keys = nil
+
+	if err := client.URL(s.URL).Put(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.URL(s.URL).Post(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !uuidPattern.MatchString(keys[0]) || keys[1] != "" {
+		t.Errorf("Expected only PUT to get a key, got %v instead", keys)
+	}
+}
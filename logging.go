@@ -0,0 +1,282 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wedeploy
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// LogLevel of a log entry
+type LogLevel int
+
+const (
+	// LogDebug is used for request summaries
+	LogDebug LogLevel = iota
+
+	// LogInfo is used for successful responses
+	LogInfo
+
+	// LogWarn is used for responses with 4xx status codes
+	LogWarn
+
+	// LogError is used for responses with 5xx status codes and transport errors
+	LogError
+)
+
+func (l LogLevel) String() string {
+	switch l {
+	case LogDebug:
+		return "DEBUG"
+	case LogInfo:
+		return "INFO"
+	case LogWarn:
+		return "WARN"
+	case LogError:
+		return "ERROR"
+	}
+
+	return fmt.Sprintf("LogLevel(%d)", int(l))
+}
+
+// Logger receives structured log entries.
+// Implementations must be safe for concurrent use.
+type Logger interface {
+	Log(level LogLevel, msg string, fields map[string]interface{})
+}
+
+// LoggerFunc is an adapter to use a function as a Logger
+type LoggerFunc func(level LogLevel, msg string, fields map[string]interface{})
+
+// Log calls f(level, msg, fields)
+func (f LoggerFunc) Log(level LogLevel, msg string, fields map[string]interface{}) {
+	f(level, msg, fields)
+}
+
+// StdLogger adapts a standard library logger, writing the fields
+// sorted by key, such as: INFO response id=1 method=GET status=200
+func StdLogger(l *log.Logger) Logger {
+	return LoggerFunc(func(level LogLevel, msg string, fields map[string]interface{}) {
+		var keys []string
+
+		for key := range fields {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+
+		var b bytes.Buffer
+		b.WriteString(level.String() + " " + msg)
+
+		for _, key := range keys {
+			fmt.Fprintf(&b, " %s=%v", key, fields[key])
+		}
+
+		l.Println(b.String())
+	})
+}
+
+// LogSettings for logging requests
+type LogSettings struct {
+	// Level is the minimum level of the entries logged
+	Level LogLevel
+
+	// Bodies enables logging request and response bodies
+	Bodies bool
+
+	// MaxBodySize is the size bodies are truncated to (default 1024 bytes)
+	MaxBodySize int
+
+	// SensitiveHeaders are redacted from the logs,
+	// in addition to DefaultSensitiveHeaders
+	SensitiveHeaders []string
+
+	// SensitiveFields are JSON fields redacted from the bodies
+	SensitiveFields []string
+}
+
+// DefaultSensitiveHeaders are always redacted from the logs
+var DefaultSensitiveHeaders = []string{
+	"Authorization",
+	"Cookie",
+	"Proxy-Authorization",
+	"Set-Cookie",
+}
+
+// WithLogger logs a summary of the requests and responses of the client
+func WithLogger(l Logger, s LogSettings) Option {
+	if s.MaxBodySize <= 0 {
+		s.MaxBodySize = 1024
+	}
+
+	var headers = map[string]bool{}
+
+	for _, h := range append(DefaultSensitiveHeaders, s.SensitiveHeaders...) {
+		headers[http.CanonicalHeaderKey(h)] = true
+	}
+
+	var fields []*regexp.Regexp
+
+	for _, f := range s.SensitiveFields {
+		fields = append(fields, regexp.MustCompile(
+			`("`+regexp.QuoteMeta(f)+`"\s*:\s*)("(?:[^"\\]|\\.)*"|[^,}\]\s]+)`))
+	}
+
+	return func(h *HTTPClient) {
+		h.logger = &requestLogger{
+			logger:   l,
+			settings: s,
+			headers:  headers,
+			fields:   fields,
+		}
+	}
+}
+
+type requestLogger struct {
+	logger   Logger
+	settings LogSettings
+	headers  map[string]bool
+	fields   []*regexp.Regexp
+}
+
+func (l *requestLogger) wrap(w *WeDeploy, next roundTrip) roundTrip {
+	return func(req *http.Request) (*http.Response, error) {
+		var start = time.Now()
+
+		if l.settings.Level <= LogDebug {
+			var fields = map[string]interface{}{
+				"id":      w.ID,
+				"method":  req.Method,
+				"url":     redactURL(req.URL),
+				"headers": l.redactHeader(req.Header),
+			}
+
+			if l.settings.Bodies && w.uncompressedBody != nil {
+				fields["body"] = l.body(bytes.NewReader(w.uncompressedBody))
+			} else if l.settings.Bodies && req.GetBody != nil {
+				if body, err := req.GetBody(); err == nil {
+					fields["body"] = l.body(body)
+					body.Close()
+				}
+			}
+
+			l.logger.Log(LogDebug, "request", fields)
+		}
+
+		var resp, err = next(req)
+		var level = LogInfo
+		var fields = map[string]interface{}{
+			"id":       w.ID,
+			"method":   req.Method,
+			"url":      redactURL(req.URL),
+			"duration": time.Since(start),
+		}
+
+		switch {
+		case err != nil:
+			level = LogError
+			fields["error"] = err.Error()
+		case resp.StatusCode >= 500:
+			level = LogError
+		case resp.StatusCode >= 400:
+			level = LogWarn
+		}
+
+		if resp != nil {
+			fields["status"] = resp.StatusCode
+		}
+
+		if level < l.settings.Level {
+			return resp, err
+		}
+
+		if resp != nil && l.settings.Bodies && resp.Body != nil {
+			fields["body"] = l.peekBody(resp)
+		}
+
+		l.logger.Log(level, "response", fields)
+		return resp, err
+	}
+}
+
+func (l *requestLogger) redactHeader(h http.Header) map[string]string {
+	var m = map[string]string{}
+
+	for key, values := range h {
+		if l.headers[http.CanonicalHeaderKey(key)] {
+			m[key] = "REDACTED"
+			continue
+		}
+
+		m[key] = strings.Join(values, ", ")
+	}
+
+	return m
+}
+
+// body reads a truncated body
+func (l *requestLogger) body(r io.Reader) string {
+	var b, _ = ioutil.ReadAll(io.LimitReader(r, int64(l.settings.MaxBodySize)+1))
+	return l.redactBody(b)
+}
+
+// peekBody reads a truncated response body,
+// keeping it available to be read from the response
+func (l *requestLogger) peekBody(resp *http.Response) string {
+	var b, err = ioutil.ReadAll(io.LimitReader(resp.Body, int64(l.settings.MaxBodySize)+1))
+
+	resp.Body = &prefixedBody{
+		Reader: io.MultiReader(bytes.NewReader(b), errReader{err}, resp.Body),
+		Closer: resp.Body,
+	}
+
+	return l.redactBody(b)
+}
+
+// redactBody truncates the body and redacts its sensitive fields,
+// including a value cut by the truncation
+func (l *requestLogger) redactBody(b []byte) string {
+	var truncated = len(b) > l.settings.MaxBodySize
+
+	if truncated {
+		b = b[:l.settings.MaxBodySize]
+	}
+
+	for _, f := range l.fields {
+		b = f.ReplaceAll(b, []byte(`${1}"REDACTED"`))
+	}
+
+	if truncated {
+		return string(b) + "..."
+	}
+
+	return string(b)
+}
+
+type prefixedBody struct {
+	io.Reader
+	io.Closer
+}
+
+// errReader returns the error interrupting the read of a peeked body
+type errReader struct {
+	err error
+}
+
+func (e errReader) Read(p []byte) (int, error) {
+	if e.err != nil {
+		return 0, e.err
+	}
+
+	return 0, io.EOF
+}
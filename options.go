@@ -0,0 +1,46 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wedeploy
+
+import "net/http"
+
+// Option for configuring a HTTPClient
+type Option func(*HTTPClient)
+
+// WithAuth sets the credentials used by the requests of the client:
+// either a token or an username and password
+func WithAuth(args ...string) Option {
+	return func(h *HTTPClient) {
+		h.headers.Set("Authorization", authorization(args...))
+	}
+}
+
+// WithBaseURL sets the base URL for resolving relative request URLs
+func WithBaseURL(uri string) Option {
+	return func(h *HTTPClient) {
+		h.baseURL = uri
+	}
+}
+
+// WithHeader adds a default header to the requests of the client
+func WithHeader(key, value string) Option {
+	return func(h *HTTPClient) {
+		h.headers.Add(key, value)
+	}
+}
+
+// WithHTTP sets the HTTP Client used by the client
+func WithHTTP(hc *http.Client) Option {
+	return func(h *HTTPClient) {
+		h.http = hc
+	}
+}
+
+// WithUserAgent sets the User-Agent of the requests of the client
+func WithUserAgent(userAgent string) Option {
+	return func(h *HTTPClient) {
+		h.userAgent = userAgent
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wedeploy
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestNewHTTPClientOptions(t *testing.T) {
+	hc := NewHTTPClient(
+		WithBaseURL("https://data-example.wedeploy.io"),
+		WithHeader("X-Tenant", "liferay"),
+		WithUserAgent("my-app/1.0"),
+		WithAuth("admin", "safe"))
+
+	req := hc.URL("books", "1")
+
+	assertURI(t, "https://data-example.wedeploy.io/books/1", req.URL)
+
+	var cases = map[string]string{
+		"X-Tenant":      "liferay",
+		"User-Agent":    "my-app/1.0",
+		"Authorization": "Basic YWRtaW46c2FmZQ==",
+		"Accept":        "application/json",
+	}
+
+	for key, want := range cases {
+		if got := req.Headers.Get(key); got != want {
+			t.Errorf("Expected header %s=%s, got %s instead", key, want, got)
+		}
+	}
+
+	if hc.BaseURL() != "https://data-example.wedeploy.io" {
+		t.Errorf("Unexpected base URL %v", hc.BaseURL())
+	}
+}
+
+func TestNewHTTPClientAbsoluteURL(t *testing.T) {
+	hc := NewHTTPClient(WithBaseURL("https://data-example.wedeploy.io"))
+	req := hc.URL("http://example.com/books")
+	assertURI(t, "http://example.com/books", req.URL)
+}
+
+func TestNewHTTPClientTokenAuth(t *testing.T) {
+	hc := NewHTTPClient(WithAuth("myToken"))
+	req := hc.URL("http://example.com/")
+
+	if got := req.Headers.Get("Authorization"); got != "Bearer myToken" {
+		t.Errorf("Unexpected Authorization header %v", got)
+	}
+}
+
+func TestNewHTTPClientHeadersAreCopied(t *testing.T) {
+	hc := NewHTTPClient(WithHeader("X-Tenant", "liferay"))
+	req := hc.URL("http://example.com/")
+	req.Header("X-Tenant", "other")
+
+	if got := hc.URL("http://example.com/").Headers["X-Tenant"]; len(got) != 1 {
+		t.Errorf("Expected client default headers not to change, got %v", got)
+	}
+}
+
+func TestPathBoundToClient(t *testing.T) {
+	setupServer()
+	defer teardownServer()
+
+	mux.HandleFunc("/books/1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s", r.Header.Get("X-Tenant"))
+	})
+
+	// the default client can't reach the test server
+	client.SetHTTP(&http.Client{})
+
+	hc := NewHTTPClient(
+		WithBaseURL("http://example.com"),
+		WithHeader("X-Tenant", "liferay"),
+		WithHTTP(&http.Client{
+			Transport: &http.Transport{
+				Proxy: func(req *http.Request) (*url.URL, error) {
+					return url.Parse(server.URL)
+				},
+			},
+		}))
+
+	req := hc.URL("books").Path("1")
+
+	if req.getClient() != hc {
+		t.Errorf("Expected request derived with Path to be bound to its client")
+	}
+
+	if err := req.Get(); err != nil {
+		t.Error(err)
+	}
+
+	assertTextualBody(t, "liferay", req.Response.Body)
+}
@@ -0,0 +1,173 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+)
+
+// Canonical JSON of the query, which is the same for queries that only
+// differ on the order of their filters, searches, highlights, and
+// aggregations, or on how their and / or filters are nested.
+// Object keys are sorted, nested and / or filters are flattened,
+// and / or filters with a single filter are replaced by it, and the
+// filters of the query and of and / or filters are sorted.
+// The order of the sort fields is kept.
+func (b *Builder) Canonical() ([]byte, error) {
+	var m, err = b.canonicalMap()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return marshal(m)
+}
+
+// canonicalMap is the canonical query decoded as a map
+func (b *Builder) canonicalMap() (map[string]interface{}, error) {
+	var bin, err = json.Marshal(b)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var m map[string]interface{}
+	var d = json.NewDecoder(bytes.NewReader(bin))
+	d.UseNumber()
+
+	if err := d.Decode(&m); err != nil {
+		return nil, err
+	}
+
+	for _, key := range []string{"filter", "search"} {
+		if filters, ok := m[key].([]interface{}); ok {
+			m[key] = canonicalFilters("and", filters)
+		}
+	}
+
+	for _, key := range []string{"aggregation", "highlight"} {
+		if list, ok := m[key].([]interface{}); ok {
+			sortValues(list)
+		}
+	}
+
+	return m, nil
+}
+
+// Hash of the canonical JSON of the query, as a hex encoded SHA-256 hash
+func (b *Builder) Hash() (string, error) {
+	var bin, err = b.Canonical()
+
+	if err != nil {
+		return "", err
+	}
+
+	var sum = sha256.Sum256(bin)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// canonicalFilters combined by the operator, flattening the filters
+// that combine other filters with the same operator
+func canonicalFilters(operator string, filters []interface{}) []interface{} {
+	var list = []interface{}{}
+
+	for _, f := range filters {
+		f = canonicalFilter(f)
+
+		if nested, ok := combined(f, operator); ok {
+			list = append(list, nested...)
+		} else {
+			list = append(list, f)
+		}
+	}
+
+	sortValues(list)
+	return list
+}
+
+func canonicalFilter(f interface{}) interface{} {
+	var m, ok = f.(map[string]interface{})
+
+	if !ok || len(m) != 1 {
+		return f
+	}
+
+	for operator, v := range m {
+		var filters, ok = v.([]interface{})
+
+		if !ok || (operator != "and" && operator != "or") {
+			return f
+		}
+
+		filters = canonicalFilters(operator, filters)
+
+		if len(filters) == 1 {
+			return filters[0]
+		}
+
+		return map[string]interface{}{operator: filters}
+	}
+
+	return f
+}
+
+// combined gets the filters of a filter combining them with the operator
+func combined(f interface{}, operator string) ([]interface{}, bool) {
+	var m, ok = f.(map[string]interface{})
+
+	if !ok || len(m) != 1 {
+		return nil, false
+	}
+
+	filters, ok := m[operator].([]interface{})
+	return filters, ok
+}
+
+// sortValues by their JSON encoding
+func sortValues(list []interface{}) {
+	var keys = make([]string, len(list))
+
+	for i, v := range list {
+		var bin, _ = marshal(v)
+		keys[i] = string(bin)
+	}
+
+	sort.Sort(byKey{list, keys})
+}
+
+type byKey struct {
+	list []interface{}
+	keys []string
+}
+
+func (b byKey) Len() int {
+	return len(b.list)
+}
+
+func (b byKey) Less(i, j int) bool {
+	return b.keys[i] < b.keys[j]
+}
+
+func (b byKey) Swap(i, j int) {
+	b.list[i], b.list[j] = b.list[j], b.list[i]
+	b.keys[i], b.keys[j] = b.keys[j], b.keys[i]
+}
+
+// marshal without escaping HTML characters such as > on operators
+func marshal(v interface{}) ([]byte, error) {
+	var b bytes.Buffer
+	var e = json.NewEncoder(&b)
+	e.SetEscapeHTML(false)
+
+	if err := e.Encode(v); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSuffix(b.Bytes(), []byte("\n")), nil
+}
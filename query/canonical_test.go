@@ -0,0 +1,176 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"testing"
+
+	"github.com/henvic/wedeploy-sdk-go/aggregation"
+	"github.com/henvic/wedeploy-sdk-go/filter"
+	"github.com/henvic/wedeploy-sdk-go/geo"
+)
+
+func TestCanonical(t *testing.T) {
+	var q = New().
+		Filter(filter.Or(
+			filter.Equal("genre", "drama"),
+			filter.Or(filter.Equal("genre", "comedy"), filter.Equal("genre", "history")))).
+		Filter("year", ">", 1600).
+		Highlight("title").
+		Highlight("author").
+		Aggregate("years", "year", "histogram").
+		Sort("year", "desc").
+		Sort("title").
+		Limit(10)
+
+	var want = `{"aggregation":[{"year":{"name":"years","operator":"histogram"}}],` +
+		`"filter":[` +
+		`{"or":[{"genre":{"operator":"=","value":"comedy"}},` +
+		`{"genre":{"operator":"=","value":"drama"}},` +
+		`{"genre":{"operator":"=","value":"history"}}]},` +
+		`{"year":{"operator":">","value":1600}}],` +
+		`"highlight":["author","title"],"limit":10,` +
+		`"sort":[{"year":"desc"},{"title":"asc"}]}`
+
+	var got, err = q.Canonical()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(got) != want {
+		t.Errorf("Expected canonical JSON %v, got %s instead", want, got)
+	}
+}
+
+func TestCanonicalEquivalent(t *testing.T) {
+	var cases = [][2]*Builder{
+		{
+			Filter("a", 1).Filter("b", 2),
+			Filter("b", 2).Filter("a", 1),
+		},
+		{
+			Filter(filter.And(filter.Equal("a", 1), filter.Equal("b", 2))),
+			Filter("b", 2).Filter("a", 1),
+		},
+		{
+			Filter(filter.Or(filter.Equal("a", 1), filter.Or(filter.Equal("b", 2), filter.Equal("c", 3)))),
+			Filter(filter.Or(filter.Or(filter.Equal("c", 3), filter.Equal("a", 1)), filter.Equal("b", 2))),
+		},
+		{
+			Filter(filter.And(filter.Equal("a", 1))),
+			Filter("a", 1),
+		},
+		{
+			Filter(filter.Or(filter.And(filter.Equal("a", 1)), filter.Equal("b", 2))),
+			Filter(filter.Or(filter.Equal("b", 2), filter.Equal("a", 1))),
+		},
+		{
+			Search("hamlet").Search("title", "prince"),
+			Search("title", "prince").Search("hamlet"),
+		},
+		{
+			Aggregate(aggregation.Avg("avg", "year")).Aggregate(aggregation.Max("max", "year")),
+			Aggregate(aggregation.Max("max", "year")).Aggregate(aggregation.Avg("avg", "year")),
+		},
+		{
+			Filter("year", ">", 1600.0),
+			Filter("year", ">", 1600),
+		},
+	}
+
+	for _, c := range cases {
+		var h1, err1 = c[0].Hash()
+		var h2, err2 = c[1].Hash()
+
+		if err1 != nil || err2 != nil {
+			t.Errorf("Expected no errors, got %v and %v instead", err1, err2)
+		}
+
+		if h1 != h2 {
+			var c1, _ = c[0].Canonical()
+			var c2, _ = c[1].Canonical()
+			t.Errorf("Expected equivalent queries to have the same hash, got %s and %s instead", c1, c2)
+		}
+	}
+}
+
+func TestCanonicalDifferent(t *testing.T) {
+	var cases = [][2]*Builder{
+		{
+			Sort("a").Sort("b"),
+			Sort("b").Sort("a"),
+		},
+		{
+			Filter(filter.Or(filter.Equal("a", 1), filter.Equal("b", 2))),
+			Filter(filter.And(filter.Equal("a", 1), filter.Equal("b", 2))),
+		},
+		{
+			Filter(filter.Or(filter.Equal("a", 1), filter.And(filter.Equal("b", 2), filter.Equal("c", 3)))),
+			Filter(filter.Or(filter.Equal("a", 1), filter.Equal("b", 2), filter.Equal("c", 3))),
+		},
+		{
+			Filter("a", 1).Limit(10),
+			Filter("a", 1).Limit(20),
+		},
+		{
+			Filter("a", 1),
+			Search("a", "=", 1),
+		},
+	}
+
+	for _, c := range cases {
+		var h1, _ = c[0].Hash()
+		var h2, _ = c[1].Hash()
+
+		if h1 == h2 {
+			var c1, _ = c[0].Canonical()
+			t.Errorf("Expected different queries to have different hashes, got %s for both", c1)
+		}
+	}
+}
+
+func TestCanonicalDoesNotChangeQuery(t *testing.T) {
+	var q = Filter("b", 2).Filter("a", 1)
+	var c = q.Clone()
+
+	if _, err := q.Canonical(); err != nil {
+		t.Fatal(err)
+	}
+
+	var got, _ = q.Hash()
+	var want, _ = c.Hash()
+
+	if got != want || (*q.BFilter)[0]["b"] == nil {
+		t.Errorf("Expected query not to be changed")
+	}
+}
+
+func TestHash(t *testing.T) {
+	var got, err = New().Hash()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// SHA-256 of {}
+	var want = "44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a"
+
+	if got != want {
+		t.Errorf("Expected hash %v, got %v instead", want, got)
+	}
+}
+
+func TestCanonicalError(t *testing.T) {
+	var q = Filter(filter.Distance("location", geo.Point{0, 0}, "5 parsecs"))
+
+	if _, err := q.Canonical(); err == nil {
+		t.Errorf("Expected error for invalid distance")
+	}
+
+	if _, err := q.Hash(); err == nil {
+		t.Errorf("Expected error for invalid distance")
+	}
+}
@@ -0,0 +1,236 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/henvic/wedeploy-sdk-go/filter"
+)
+
+// Merge a copy of the other query into the query:
+// filters and searches are appended, so that they are combined with
+// the existing ones with and, sort fields and aggregations are appended,
+// highlights are appended unless already present, and the type, limit
+// and offset of the other query override the existing ones, if set.
+func (b *Builder) Merge(other *Builder) *Builder {
+	if other == nil {
+		return b
+	}
+
+	var o = other.Clone()
+
+	if o.Type != "" {
+		b.Type = o.Type
+	}
+
+	b.BFilter = appendFilters(b.BFilter, o.BFilter)
+	b.BSearch = appendFilters(b.BSearch, o.BSearch)
+
+	if o.BSort != nil {
+		if b.BSort == nil {
+			b.BSort = &[]map[string]string{}
+		}
+
+		*b.BSort = append(*b.BSort, *o.BSort...)
+	}
+
+	if o.Aggregation != nil {
+		for _, a := range *o.Aggregation {
+			b.Aggregate(&a)
+		}
+	}
+
+	if o.Highlights != nil {
+		for _, h := range *o.Highlights {
+			if !b.highlighted(h) {
+				b.Highlight(h)
+			}
+		}
+	}
+
+	if o.BLimit != nil {
+		b.BLimit = o.BLimit
+	}
+
+	if o.BOffset != nil {
+		b.BOffset = o.BOffset
+	}
+
+	return b
+}
+
+func (b *Builder) highlighted(field string) bool {
+	if b.Highlights == nil {
+		return false
+	}
+
+	for _, h := range *b.Highlights {
+		if h == field {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ChangeKind is the kind of a change between queries
+type ChangeKind string
+
+const (
+	// Added part of a query
+	Added ChangeKind = "added"
+
+	// Removed part of a query
+	Removed ChangeKind = "removed"
+
+	// Changed part of a query
+	Changed ChangeKind = "changed"
+)
+
+// Change between queries
+type Change struct {
+	// Part of the query: type, filter, search, sort, limit, offset,
+	// highlight, or aggregation
+	Part string
+	Kind ChangeKind
+
+	// From and To are the canonical JSON of the value before and after
+	// the change, if any
+	From json.RawMessage
+	To   json.RawMessage
+}
+
+func (c Change) String() string {
+	switch c.Kind {
+	case Added:
+		return fmt.Sprintf("%s added: %s", c.Part, c.To)
+	case Removed:
+		return fmt.Sprintf("%s removed: %s", c.Part, c.From)
+	}
+
+	return fmt.Sprintf("%s changed from %s to %s", c.Part, c.From, c.To)
+}
+
+// parts of a query compared by Diff, with whether their order is ignored
+var parts = []struct {
+	name      string
+	unordered bool
+}{
+	{"type", false},
+	{"filter", true},
+	{"search", true},
+	{"sort", false},
+	{"limit", false},
+	{"offset", false},
+	{"highlight", true},
+	{"aggregation", true},
+}
+
+// Diff explains what changed from query a to query b, comparing their
+// canonical forms. Filters, searches, highlights, and aggregations are
+// added or removed one by one, while other parts, including the list of
+// sort fields, are compared as a whole. A nil query is an empty query.
+func Diff(a, b *Builder) ([]Change, error) {
+	var from, to map[string]interface{}
+	var err error
+
+	if from, err = canonicalOrEmpty(a); err != nil {
+		return nil, err
+	}
+
+	if to, err = canonicalOrEmpty(b); err != nil {
+		return nil, err
+	}
+
+	var changes []Change
+
+	for _, p := range parts {
+		if p.unordered {
+			changes = append(changes, diffElements(p.name, from[p.name], to[p.name])...)
+			continue
+		}
+
+		var f, t = encode(from[p.name]), encode(to[p.name])
+
+		switch {
+		case string(f) == string(t):
+		case f == nil:
+			changes = append(changes, Change{Part: p.name, Kind: Added, To: t})
+		case t == nil:
+			changes = append(changes, Change{Part: p.name, Kind: Removed, From: f})
+		default:
+			changes = append(changes, Change{Part: p.name, Kind: Changed, From: f, To: t})
+		}
+	}
+
+	return changes, nil
+}
+
+func canonicalOrEmpty(b *Builder) (map[string]interface{}, error) {
+	if b == nil {
+		return map[string]interface{}{}, nil
+	}
+
+	return b.canonicalMap()
+}
+
+// diffElements of a list, ignoring their order
+func diffElements(part string, from, to interface{}) []Change {
+	var f, _ = from.([]interface{})
+	var t, _ = to.([]interface{})
+	var remaining = map[string]int{}
+	var changes []Change
+
+	for _, v := range t {
+		remaining[string(encode(v))]++
+	}
+
+	for _, v := range f {
+		var e = encode(v)
+
+		if remaining[string(e)] > 0 {
+			remaining[string(e)]--
+			continue
+		}
+
+		changes = append(changes, Change{Part: part, Kind: Removed, From: e})
+	}
+
+	for _, v := range t {
+		var e = encode(v)
+
+		if remaining[string(e)] > 0 {
+			remaining[string(e)]--
+			changes = append(changes, Change{Part: part, Kind: Added, To: e})
+		}
+	}
+
+	return changes
+}
+
+// encode a canonical value, which is nil if the value is missing
+func encode(v interface{}) json.RawMessage {
+	if v == nil {
+		return nil
+	}
+
+	var bin, _ = marshal(v)
+	return bin
+}
+
+func appendFilters(filters, other *[]filter.Filter) *[]filter.Filter {
+	if other == nil {
+		return filters
+	}
+
+	if filters == nil {
+		filters = &[]filter.Filter{}
+	}
+
+	*filters = append(*filters, *other...)
+	return filters
+}
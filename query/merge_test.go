@@ -0,0 +1,148 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/henvic/wedeploy-sdk-go/aggregation"
+	"github.com/henvic/wedeploy-sdk-go/filter"
+	"github.com/henvic/wedeploy-sdk-go/jsonlib"
+)
+
+func TestMerge(t *testing.T) {
+	var tenant = Filter("tenant", "acme").Highlight("title").Limit(100)
+	var user = Filter("year", ">", 1600).
+		Search("hamlet").
+		Highlight("title").
+		Highlight("author").
+		Aggregate(aggregation.Avg("avg_year", "year")).
+		Limit(10).
+		Offset(20).
+		Count()
+	var ui = Sort("year", "desc").Sort("title")
+
+	var got = New().Merge(tenant).Merge(user).Merge(ui).Merge(nil)
+
+	var want = `{
+    "type": "count",
+    "filter": [
+        {"tenant": {"operator": "=", "value": "acme"}},
+        {"year": {"operator": ">", "value": 1600}}
+    ],
+    "search": [
+        {"*": {"operator": "match", "value": "hamlet"}}
+    ],
+    "aggregation": [
+        {"year": {"name": "avg_year", "operator": "avg"}}
+    ],
+    "highlight": ["title", "author"],
+    "limit": 10,
+    "offset": 20,
+    "sort": [{"year": "desc"}, {"title": "asc"}]
+}`
+
+	jsonlib.AssertJSONMarshal(t, want, got)
+
+	// the merged queries are copied
+	(*got.BFilter)[0]["tenant"] = "changed"
+	*got.BLimit = 5
+
+	if *tenant.BLimit != 100 || *user.BLimit != 10 || (*tenant.BFilter)[0]["tenant"] == "changed" {
+		t.Errorf("Expected merged queries not to be changed")
+	}
+}
+
+func TestMergeKeepsUnset(t *testing.T) {
+	var got = Filter("a", 1).Limit(10).Offset(5).Fetch().Merge(Filter("b", 2))
+
+	if got.Type != "fetch" || *got.BLimit != 10 || *got.BOffset != 5 || len(*got.BFilter) != 2 {
+		t.Errorf("Expected unset parts not to override the query, got %+v instead", got)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	var a = Filter("tenant", "acme").
+		Filter("year", ">", 1600).
+		Highlight("title").
+		Sort("year").
+		Limit(10).
+		Count()
+
+	var b = Filter(filter.And(filter.Equal("genre", "drama"), filter.Equal("tenant", "acme"))).
+		Highlight("title").
+		Highlight("author").
+		Sort("year", "desc").
+		Limit(20).
+		Offset(10)
+
+	var got, err = Diff(a, b)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var want = []string{
+		`type removed: "count"`,
+		`filter removed: {"year":{"operator":">","value":1600}}`,
+		`filter added: {"genre":{"operator":"=","value":"drama"}}`,
+		`sort changed from [{"year":"asc"}] to [{"year":"desc"}]`,
+		`limit changed from 10 to 20`,
+		`offset added: 10`,
+		`highlight added: "author"`,
+	}
+
+	var list []string
+
+	for _, c := range got {
+		list = append(list, c.String())
+	}
+
+	if !reflect.DeepEqual(list, want) {
+		t.Errorf("Expected changes %q, got %q instead", want, list)
+	}
+
+	if got[0].Part != "type" || got[0].Kind != Removed || string(got[0].From) != `"count"` || got[0].To != nil {
+		t.Errorf("Unexpected change %+v", got[0])
+	}
+}
+
+func TestDiffEquivalent(t *testing.T) {
+	var a = Filter("a", 1).Filter("b", 2).Highlight("x").Highlight("y")
+	var b = Filter("b", 2).Filter("a", 1).Highlight("y").Highlight("x")
+
+	if changes, err := Diff(a, b); err != nil || len(changes) != 0 {
+		t.Errorf("Expected no changes, got %v (%v) instead", changes, err)
+	}
+}
+
+func TestDiffDuplicates(t *testing.T) {
+	var changes, err = Diff(Highlight("x"), Highlight("x").Highlight("x"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(changes) != 1 || changes[0].String() != `highlight added: "x"` {
+		t.Errorf("Expected a highlight to be added, got %v instead", changes)
+	}
+}
+
+func TestDiffNil(t *testing.T) {
+	var changes, err = Diff(nil, Limit(10))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(changes) != 1 || changes[0].String() != "limit added: 10" {
+		t.Errorf("Expected limit to be added, got %v instead", changes)
+	}
+
+	if changes, _ = Diff(Limit(10), nil); len(changes) != 1 || changes[0].Kind != Removed {
+		t.Errorf("Expected limit to be removed, got %v instead", changes)
+	}
+}
@@ -95,6 +95,57 @@ func (b *Builder) Aggregate(ai ...interface{}) *Builder {
 	return b
 }
 
+// Clone creates a deep copy of the query builder
+func (b *Builder) Clone() *Builder {
+	var c = &Builder{
+		Type: b.Type,
+	}
+
+	if b.Aggregation != nil {
+		var aggregations = make([]aggregation.Aggregation, len(*b.Aggregation))
+
+		for i, a := range *b.Aggregation {
+			aggregations[i] = *a.Clone()
+		}
+
+		c.Aggregation = &aggregations
+	}
+
+	c.BFilter = cloneFilters(b.BFilter)
+	c.BSearch = cloneFilters(b.BSearch)
+
+	if b.Highlights != nil {
+		var highlights = append([]string{}, *b.Highlights...)
+		c.Highlights = &highlights
+	}
+
+	if b.BOffset != nil {
+		var offset = *b.BOffset
+		c.BOffset = &offset
+	}
+
+	if b.BLimit != nil {
+		var limit = *b.BLimit
+		c.BLimit = &limit
+	}
+
+	if b.BSort != nil {
+		var sort = make([]map[string]string, len(*b.BSort))
+
+		for i, s := range *b.BSort {
+			sort[i] = map[string]string{}
+
+			for k, v := range s {
+				sort[i][k] = v
+			}
+		}
+
+		c.BSort = &sort
+	}
+
+	return c
+}
+
 // Count sets the query type to count
 func (b *Builder) Count() *Builder {
 	b.Type = "count"
@@ -197,3 +248,17 @@ func (b *Builder) Sort(field string, direction ...string) *Builder {
 
 	return b
 }
+
+func cloneFilters(filters *[]filter.Filter) *[]filter.Filter {
+	if filters == nil {
+		return nil
+	}
+
+	var c = make([]filter.Filter, len(*filters))
+
+	for i, f := range *filters {
+		c[i] = *f.Clone()
+	}
+
+	return &c
+}
@@ -48,6 +48,41 @@ func TestAggregate2(t *testing.T) {
 	jsonlib.AssertJSONMarshal(t, want, got)
 }
 
+func TestClone(t *testing.T) {
+	var want = `{
+    "type": "count",
+    "filter": [{"age": {"operator": ">", "value": 12}}],
+    "search": [{"*": {"operator": "match", "value": "foo"}}],
+    "highlight": ["name"],
+    "sort": [{"name": "asc"}],
+    "limit": 10,
+    "offset": 5,
+    "aggregation": [{"f": {"operator": "min", "name": "a"}}]
+}`
+
+	var original = Count().
+		Filter(filter.Gt("age", 12)).
+		Search("foo").
+		Highlight("name").
+		Sort("name").
+		Limit(10).
+		Offset(5).
+		Aggregate("a", "f", "min")
+
+	var got = original.Clone()
+	jsonlib.AssertJSONMarshal(t, want, got)
+
+	got.Filter("name", "foo").Search("bar").Highlight("bar").Sort("age").
+		Limit(1).Offset(1).Aggregate("b", "f", "max").Fetch()
+	(*got.BSort)[0]["name"] = "desc"
+
+	jsonlib.AssertJSONMarshal(t, want, original)
+}
+
+func TestCloneEmpty(t *testing.T) {
+	jsonlib.AssertJSONMarshal(t, `{}`, New().Clone())
+}
+
 func TestCount(t *testing.T) {
 	var want = `{"type":"count"}`
 	var got = Count()
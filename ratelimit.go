@@ -0,0 +1,330 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wedeploy
+
+import (
+	"container/list"
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WithRateLimit limits the requests of the client with a token bucket
+// refilled at rate requests per second, holding up to burst requests.
+// A rate of zero or less doesn't limit the requests, except by the
+// X-RateLimit-* and Retry-After response headers.
+func WithRateLimit(rate float64, burst int) Option {
+	return func(h *HTTPClient) {
+		h.limiter().setRate(rate, burst)
+	}
+}
+
+// WithMaxInFlight limits the number of concurrent requests of the client.
+// A limit of zero or less means no limit.
+func WithMaxInFlight(n int) Option {
+	return func(h *HTTPClient) {
+		h.limiter().setMaxInFlight(n)
+	}
+}
+
+// SetRateLimit changes the rate limit of the client at runtime
+func (h *HTTPClient) SetRateLimit(rate float64, burst int) {
+	h.limiter().setRate(rate, burst)
+}
+
+// SetMaxInFlight changes the concurrent requests limit of the client at runtime
+func (h *HTTPClient) SetMaxInFlight(n int) {
+	h.limiter().setMaxInFlight(n)
+}
+
+func (h *HTTPClient) limiter() *requestLimiter {
+	h.limitsOnce.Do(func() {
+		h.limits = &requestLimiter{
+			rate:     &tokenBucket{now: time.Now},
+			inFlight: &semaphore{waiters: list.New()},
+		}
+	})
+
+	return h.limits
+}
+
+// requestLimiter waits for the rate and concurrency limits before sending
+// a request, and adjusts the rate limit from the response headers
+type requestLimiter struct {
+	rate     *tokenBucket
+	inFlight *semaphore
+	on       int32
+}
+
+func (l *requestLimiter) enabled() bool {
+	return atomic.LoadInt32(&l.on) == 1
+}
+
+func (l *requestLimiter) setRate(rate float64, burst int) {
+	l.rate.set(rate, burst)
+	atomic.StoreInt32(&l.on, 1)
+}
+
+func (l *requestLimiter) setMaxInFlight(n int) {
+	l.inFlight.set(n)
+	atomic.StoreInt32(&l.on, 1)
+}
+
+func (l *requestLimiter) wrap(next roundTrip) roundTrip {
+	return func(req *http.Request) (*http.Response, error) {
+		var ctx = req.Context()
+
+		if err := l.rate.wait(ctx); err != nil {
+			return nil, err
+		}
+
+		if err := l.inFlight.acquire(ctx); err != nil {
+			return nil, err
+		}
+
+		var resp, err = next(req)
+		l.inFlight.release()
+
+		if resp != nil {
+			l.rate.update(resp)
+		}
+
+		return resp, err
+	}
+}
+
+type tokenBucket struct {
+	mutex  sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	pause  time.Time
+	now    func() time.Time
+}
+
+func (b *tokenBucket) set(rate float64, burst int) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if burst < 1 {
+		burst = 1
+	}
+
+	var initial = b.last.IsZero()
+	b.refill()
+	b.rate = rate
+	b.burst = float64(burst)
+	b.tokens = math.Min(b.tokens, b.burst)
+
+	if initial {
+		b.tokens = b.burst
+	}
+}
+
+// refill the bucket with the tokens accrued since the last call
+func (b *tokenBucket) refill() {
+	var now = b.now()
+
+	if b.rate > 0 && !b.last.IsZero() {
+		var accrued = now.Sub(b.last).Seconds() * b.rate
+		b.tokens = math.Min(b.burst, b.tokens+accrued)
+	}
+
+	b.last = now
+}
+
+// reserve a token, returning how long to wait before using it
+func (b *tokenBucket) reserve() time.Duration {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	var wait time.Duration
+
+	if b.rate > 0 {
+		b.refill()
+		b.tokens--
+
+		if b.tokens < 0 {
+			wait = time.Duration(-b.tokens / b.rate * float64(time.Second))
+		}
+	}
+
+	if pause := b.pause.Sub(b.now()); pause > wait {
+		wait = pause
+	}
+
+	return wait
+}
+
+// cancel a reservation that was not used
+func (b *tokenBucket) cancel() {
+	b.mutex.Lock()
+
+	if b.rate > 0 {
+		b.tokens = math.Min(b.burst, b.tokens+1)
+	}
+
+	b.mutex.Unlock()
+}
+
+func (b *tokenBucket) wait(ctx context.Context) error {
+	var d = b.reserve()
+
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	var timer = time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		b.cancel()
+		return ctx.Err()
+	}
+}
+
+// update the bucket from the X-RateLimit-Remaining and X-RateLimit-Reset
+// headers, or from the Retry-After header of a 429 Too Many Requests response
+func (b *tokenBucket) update(resp *http.Response) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	var now = b.now()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		if d, ok := parseRetryAfter(resp.Header.Get("Retry-After"), now); ok {
+			b.pauseUntil(now.Add(d))
+		}
+	}
+
+	remaining, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining"))
+
+	if err != nil {
+		return
+	}
+
+	if b.rate > 0 {
+		b.refill()
+		b.tokens = math.Min(b.tokens, float64(remaining))
+	}
+
+	if remaining > 0 {
+		return
+	}
+
+	if reset, ok := parseRateLimitReset(resp.Header.Get("X-RateLimit-Reset"), now); ok {
+		b.pauseUntil(reset)
+	}
+}
+
+func (b *tokenBucket) pauseUntil(t time.Time) {
+	if t.After(b.pause) {
+		b.pause = t
+	}
+}
+
+// parseRateLimitReset parses the X-RateLimit-Reset header,
+// either as an Unix timestamp or as a number of seconds from now
+func parseRateLimitReset(value string, now time.Time) (time.Time, bool) {
+	var n, err = strconv.ParseInt(value, 10, 64)
+
+	if err != nil || n < 0 {
+		return time.Time{}, false
+	}
+
+	if n > 1e9 {
+		return time.Unix(n, 0), true
+	}
+
+	return now.Add(time.Duration(n) * time.Second), true
+}
+
+// parseRetryAfter parses the Retry-After header,
+// either as a number of seconds or as a HTTP date
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if t, err := http.ParseTime(value); err == nil {
+		return t.Sub(now), true
+	}
+
+	return 0, false
+}
+
+// semaphore with a limit that can be changed while it is in use
+type semaphore struct {
+	mutex   sync.Mutex
+	max     int
+	active  int
+	waiters *list.List
+}
+
+func (s *semaphore) set(n int) {
+	s.mutex.Lock()
+	s.max = n
+
+	for s.waiters.Len() != 0 && (s.max <= 0 || s.active < s.max) {
+		s.active++
+		close(s.waiters.Remove(s.waiters.Front()).(chan struct{}))
+	}
+
+	s.mutex.Unlock()
+}
+
+func (s *semaphore) acquire(ctx context.Context) error {
+	s.mutex.Lock()
+
+	if s.max <= 0 || s.active < s.max {
+		s.active++
+		s.mutex.Unlock()
+		return nil
+	}
+
+	var ready = make(chan struct{})
+	var e = s.waiters.PushBack(ready)
+	s.mutex.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+		s.mutex.Lock()
+
+		select {
+		case <-ready:
+			// acquired concurrently with the cancellation
+			s.mutex.Unlock()
+			s.release()
+		default:
+			s.waiters.Remove(e)
+			s.mutex.Unlock()
+		}
+
+		return ctx.Err()
+	}
+}
+
+func (s *semaphore) release() {
+	s.mutex.Lock()
+
+	if s.waiters.Len() != 0 && (s.max <= 0 || s.active <= s.max) {
+		// hand the slot over to the next waiter
+		close(s.waiters.Remove(s.waiters.Front()).(chan struct{}))
+	} else {
+		s.active--
+	}
+
+	s.mutex.Unlock()
+}
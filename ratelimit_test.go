@@ -0,0 +1,264 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wedeploy
+
+import (
+	"container/list"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	t time.Time
+}
+
+func (f *fakeClock) now() time.Time {
+	return f.t
+}
+
+func TestTokenBucket(t *testing.T) {
+	var clock = &fakeClock{time.Unix(1500000000, 0)}
+	var b = &tokenBucket{now: clock.now}
+	b.set(2, 2)
+
+	var want = []time.Duration{0, 0, 500 * time.Millisecond, time.Second}
+
+	for i, w := range want {
+		if got := b.reserve(); got != w {
+			t.Errorf("Expected reservation %d to wait %v, got %v instead", i, w, got)
+		}
+	}
+
+	clock.t = clock.t.Add(time.Second)
+
+	if got := b.reserve(); got != 500*time.Millisecond {
+		t.Errorf("Expected reservation to wait 500ms, got %v instead", got)
+	}
+
+	b.cancel()
+	b.cancel()
+
+	if got := b.reserve(); got != 0 {
+		t.Errorf("Expected canceled reservations to be returned, got wait %v", got)
+	}
+}
+
+func TestTokenBucketUpdate(t *testing.T) {
+	var clock = &fakeClock{time.Unix(1500000000, 0)}
+	var b = &tokenBucket{now: clock.now}
+	b.set(10, 10)
+
+	b.update(&http.Response{
+		StatusCode: http.StatusOK,
+		Header: http.Header{
+			"X-Ratelimit-Remaining": []string{"0"},
+			"X-Ratelimit-Reset":     []string{"1500000030"},
+		},
+	})
+
+	if got := b.reserve(); got != 30*time.Second {
+		t.Errorf("Expected to wait for the rate limit reset, got %v instead", got)
+	}
+
+	b.update(&http.Response{
+		StatusCode: http.StatusTooManyRequests,
+		Header:     http.Header{"Retry-After": []string{"60"}},
+	})
+
+	if got := b.reserve(); got != time.Minute {
+		t.Errorf("Expected to wait for Retry-After, got %v instead", got)
+	}
+}
+
+func TestParseRateLimitReset(t *testing.T) {
+	var now = time.Unix(1500000000, 0)
+
+	var cases = []struct {
+		value string
+		want  time.Time
+		ok    bool
+	}{
+		{"10", now.Add(10 * time.Second), true},
+		{"1500000100", time.Unix(1500000100, 0), true},
+		{"", time.Time{}, false},
+		{"-1", time.Time{}, false},
+		{"soon", time.Time{}, false},
+	}
+
+	for _, c := range cases {
+		if got, ok := parseRateLimitReset(c.value, now); !got.Equal(c.want) || ok != c.ok {
+			t.Errorf("Expected reset %v (%v) for %q, got %v (%v) instead",
+				c.want, c.ok, c.value, got, ok)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	var now = time.Date(2015, 10, 21, 7, 28, 0, 0, time.UTC)
+
+	if got, ok := parseRetryAfter("120", now); !ok || got != 2*time.Minute {
+		t.Errorf("Expected 2m, got %v instead", got)
+	}
+
+	if got, ok := parseRetryAfter("Wed, 21 Oct 2015 07:29:00 GMT", now); !ok || got != time.Minute {
+		t.Errorf("Expected 1m, got %v instead", got)
+	}
+
+	if _, ok := parseRetryAfter("later", now); ok {
+		t.Errorf("Expected invalid Retry-After to be ignored")
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer s.Close()
+
+	var hc = NewHTTPClient(WithRateLimit(20, 1))
+	var start = time.Now()
+
+	for i := 0; i < 3; i++ {
+		if err := hc.URL(s.URL).Get(); err != nil {
+			t.Error(err)
+		}
+	}
+
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("Expected requests to be rate limited, took %v", elapsed)
+	}
+}
+
+func TestRateLimitContextCanceled(t *testing.T) {
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer s.Close()
+
+	var hc = NewHTTPClient(WithRateLimit(0.1, 1))
+
+	if err := hc.URL(s.URL).Get(); err != nil {
+		t.Error(err)
+	}
+
+	var ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	var req = hc.URL(s.URL)
+	req.SetContext(ctx)
+
+	if err := req.Get(); err != context.DeadlineExceeded {
+		t.Errorf("Expected context deadline exceeded, got %v instead", err)
+	}
+}
+
+func TestRateLimitFromHeaders(t *testing.T) {
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", "60")
+	}))
+	defer s.Close()
+
+	var hc = NewHTTPClient(WithRateLimit(0, 1))
+
+	if err := hc.URL(s.URL).Get(); err != nil {
+		t.Error(err)
+	}
+
+	var ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	var req = hc.URL(s.URL)
+	req.SetContext(ctx)
+
+	if err := req.Get(); err != context.DeadlineExceeded {
+		t.Errorf("Expected context deadline exceeded, got %v instead", err)
+	}
+}
+
+func TestMaxInFlight(t *testing.T) {
+	var active, peak int32
+
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var n = atomic.AddInt32(&active, 1)
+
+		for {
+			var p = atomic.LoadInt32(&peak)
+
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&active, -1)
+	}))
+	defer s.Close()
+
+	var hc = NewHTTPClient(WithMaxInFlight(2))
+	var wg sync.WaitGroup
+
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if err := hc.URL(s.URL).Get(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if peak > 2 {
+		t.Errorf("Expected at most 2 requests in flight, got %d instead", peak)
+	}
+}
+
+func TestSemaphore(t *testing.T) {
+	var s = &semaphore{waiters: list.New()}
+	s.set(1)
+
+	if err := s.acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	var ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+
+	if err := s.acquire(ctx); err != context.Canceled {
+		t.Errorf("Expected context canceled, got %v instead", err)
+	}
+
+	var acquired = make(chan struct{})
+
+	go func() {
+		if err := s.acquire(context.Background()); err != nil {
+			t.Error(err)
+		}
+
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("Expected acquire to wait for a free slot")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	s.set(2)
+	<-acquired
+
+	s.release()
+	s.release()
+
+	if s.active != 0 || s.waiters.Len() != 0 {
+		t.Errorf("Expected semaphore to be empty, got %d active and %d waiting",
+			s.active, s.waiters.Len())
+	}
+}
@@ -0,0 +1,420 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package recorder records HTTP interactions to cassette files
+// and replays them, for testing code without a live WeDeploy server.
+//
+// Use it as the HTTP Client of the SDK:
+//
+//	r, err := recorder.New("testdata/books.json", recorder.ModeReplay)
+//	...
+//	defer r.Stop()
+//	wedeploy.Client().SetHTTP(r.Client())
+package recorder
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Mode of the recorder
+type Mode int
+
+const (
+	// ModeRecord sends the requests and records the interactions,
+	// which are saved to the cassette on Stop
+	ModeRecord Mode = iota
+
+	// ModeReplay replays the interactions of the cassette,
+	// without sending any request
+	ModeReplay
+
+	// ModePassthrough sends the requests without recording them
+	ModePassthrough
+)
+
+// ErrInteractionNotFound is returned on replay mode for requests
+// without a recorded interaction
+var ErrInteractionNotFound = errors.New("recorder: interaction not found")
+
+// Request of an interaction.
+// Bodies that aren't valid UTF-8 are stored in RawBody, encoded as base64.
+type Request struct {
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body,omitempty"`
+	RawBody []byte      `json:"raw_body,omitempty"`
+}
+
+// Response of an interaction.
+// Bodies that aren't valid UTF-8, such as gzip bodies, are stored in
+// RawBody, encoded as base64.
+type Response struct {
+	StatusCode int         `json:"status"`
+	Headers    http.Header `json:"headers,omitempty"`
+	Body       string      `json:"body,omitempty"`
+	RawBody    []byte      `json:"raw_body,omitempty"`
+}
+
+// Interaction is a request and its response
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Cassette is the file where interactions are recorded
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Scrubber removes secrets from an interaction before it is recorded.
+// Scrubbers are also applied to the requests on replay before matching them.
+type Scrubber func(*Interaction)
+
+// ScrubHeaders replaces the values of the request and response headers with
+// REDACTED. The Authorization header is always scrubbed.
+func ScrubHeaders(names ...string) Scrubber {
+	return func(i *Interaction) {
+		for _, name := range names {
+			for _, h := range []http.Header{i.Request.Headers, i.Response.Headers} {
+				if _, ok := h[http.CanonicalHeaderKey(name)]; ok {
+					h.Set(name, "REDACTED")
+				}
+			}
+		}
+	}
+}
+
+// ScrubParams replaces the values of the request URL query string params
+// with REDACTED
+func ScrubParams(names ...string) Scrubber {
+	return func(i *Interaction) {
+		var u, err = url.Parse(i.Request.URL)
+
+		if err != nil {
+			return
+		}
+
+		var q = u.Query()
+
+		for _, name := range names {
+			if _, ok := q[name]; ok {
+				q.Set(name, "REDACTED")
+			}
+		}
+
+		u.RawQuery = q.Encode()
+		i.Request.URL = u.String()
+	}
+}
+
+// Recorder is a http.RoundTripper recording and replaying interactions
+type Recorder struct {
+	// Transport used to send requests (default http.DefaultTransport)
+	Transport http.RoundTripper
+
+	mode      Mode
+	path      string
+	cassette  *Cassette
+	used      map[*Interaction]bool
+	scrubbers []Scrubber
+	mutex     sync.Mutex
+}
+
+// New creates a recorder for the cassette file.
+// On replay mode, the cassette must exist.
+func New(path string, mode Mode, scrubbers ...Scrubber) (*Recorder, error) {
+	var r = &Recorder{
+		mode:      mode,
+		path:      path,
+		cassette:  &Cassette{},
+		used:      map[*Interaction]bool{},
+		scrubbers: append([]Scrubber{ScrubHeaders("Authorization")}, scrubbers...),
+	}
+
+	if mode != ModeReplay {
+		return r, nil
+	}
+
+	var bin, err = ioutil.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(bin, r.cassette); err != nil {
+		return nil, fmt.Errorf("recorder: can't parse cassette %v: %v", path, err)
+	}
+
+	return r, nil
+}
+
+// Mode of the recorder
+func (r *Recorder) Mode() Mode {
+	return r.mode
+}
+
+// Client creates a HTTP Client using the recorder as its transport
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// Interactions recorded or loaded from the cassette
+func (r *Recorder) Interactions() []*Interaction {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]*Interaction{}, r.cassette.Interactions...)
+}
+
+// Stop the recorder, saving the cassette on record mode
+func (r *Recorder) Stop() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+
+	r.mutex.Lock()
+	var bin, err = json.MarshalIndent(r.cassette, "", "    ")
+	r.mutex.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(r.path), 0700); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(r.path, append(bin, '\n'), 0600)
+}
+
+// RoundTrip records, replays, or passes through a request
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch r.mode {
+	case ModeReplay:
+		return r.replay(req)
+	case ModeRecord:
+		return r.record(req)
+	}
+
+	return r.transport().RoundTrip(req)
+}
+
+func (r *Recorder) transport() http.RoundTripper {
+	if r.Transport == nil {
+		return http.DefaultTransport
+	}
+
+	return r.Transport
+}
+
+func (r *Recorder) record(req *http.Request) (*http.Response, error) {
+	var i, err = newInteraction(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.transport().RoundTrip(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	i.Response = Response{
+		StatusCode: resp.StatusCode,
+		Headers:    cloneHeader(resp.Header),
+	}
+
+	i.Response.Body, i.Response.RawBody = encodeBody(body)
+
+	r.scrub(i)
+
+	r.mutex.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, i)
+	r.mutex.Unlock()
+
+	return resp, nil
+}
+
+func (r *Recorder) replay(req *http.Request) (*http.Response, error) {
+	var i, err = newInteraction(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	r.scrub(i)
+
+	var found = r.match(i.Request)
+
+	if found == nil {
+		return nil, ErrInteractionNotFound
+	}
+
+	var body = decodeBody(found.Body, found.RawBody)
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", found.StatusCode, http.StatusText(found.StatusCode)),
+		StatusCode:    found.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        cloneHeader(found.Headers),
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// match finds the first interaction not replayed yet matching the request,
+// or else the last one replayed
+func (r *Recorder) match(req Request) *Response {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var key = matchKey(req)
+	var last *Interaction
+
+	for _, i := range r.cassette.Interactions {
+		if matchKey(i.Request) != key {
+			continue
+		}
+
+		if !r.used[i] {
+			r.used[i] = true
+			return &i.Response
+		}
+
+		last = i
+	}
+
+	if last == nil {
+		return nil
+	}
+
+	return &last.Response
+}
+
+func (r *Recorder) scrub(i *Interaction) {
+	for _, s := range r.scrubbers {
+		s(i)
+	}
+}
+
+func newInteraction(req *http.Request) (*Interaction, error) {
+	var body []byte
+
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+
+		req.Body.Close()
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	var i = &Interaction{
+		Request: Request{
+			Method:  req.Method,
+			URL:     req.URL.String(),
+			Headers: cloneHeader(req.Header),
+		},
+	}
+
+	i.Request.Body, i.Request.RawBody = encodeBody(body)
+	return i, nil
+}
+
+// encodeBody as a string, or as raw bytes if it isn't valid UTF-8,
+// which would be changed when encoded as a JSON string
+func encodeBody(body []byte) (string, []byte) {
+	if utf8.Valid(body) {
+		return string(body), nil
+	}
+
+	return "", body
+}
+
+func decodeBody(body string, raw []byte) []byte {
+	if raw != nil {
+		return raw
+	}
+
+	return []byte(body)
+}
+
+// matchKey of a request: its method, URL with sorted query string params,
+// and normalized body
+func matchKey(req Request) string {
+	return req.Method + " " + normalizeURL(req.URL) + "\n" + normalizeBody(req.Body) + string(req.RawBody)
+}
+
+func normalizeURL(uri string) string {
+	var u, err = url.Parse(uri)
+
+	if err != nil {
+		return uri
+	}
+
+	u.RawQuery = u.Query().Encode()
+	return u.String()
+}
+
+// normalizeBody of a JSON body, such as a query.Builder, by sorting its keys,
+// or of a form body by sorting its fields
+func normalizeBody(body string) string {
+	var v interface{}
+	var d = json.NewDecoder(strings.NewReader(body))
+	d.UseNumber()
+
+	if err := d.Decode(&v); err == nil && !d.More() {
+		if bin, err := json.Marshal(v); err == nil {
+			return string(bin)
+		}
+	}
+
+	if strings.Contains(body, "=") {
+		if form, err := url.ParseQuery(body); err == nil {
+			return form.Encode()
+		}
+	}
+
+	return body
+}
+
+func cloneHeader(h http.Header) http.Header {
+	if h == nil {
+		return nil
+	}
+
+	var c = http.Header{}
+
+	for k, v := range h {
+		c[k] = append([]string{}, v...)
+	}
+
+	return c
+}
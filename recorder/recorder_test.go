@@ -0,0 +1,312 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package recorder
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/henvic/wedeploy-sdk-go"
+	"github.com/henvic/wedeploy-sdk-go/filter"
+)
+
+func setupCassetteDir(t *testing.T) string {
+	var dir, err = ioutil.TempDir("", "recorder")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return dir
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	var dir = setupCassetteDir(t)
+	defer os.RemoveAll(dir)
+
+	var cassette = filepath.Join(dir, "fixtures", "books.json")
+	var requests int
+
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		var body, _ = ioutil.ReadAll(r.Body)
+		w.Header().Set("X-Session", "s3cr3t")
+		fmt.Fprintf(w, `{"request":%d,"body":%q}`, requests, body)
+	}))
+	defer s.Close()
+
+	var r, err = New(cassette, ModeRecord, ScrubHeaders("X-Session"), ScrubParams("token"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var hc = wedeploy.NewHTTPClient(wedeploy.WithHTTP(r.Client()))
+	var req = hc.URL(s.URL, "books").
+		Auth("token").
+		Param("token", "abc").
+		Filter(filter.Equal("author", "Shakespeare")).
+		Filter(filter.Gt("year", 1600))
+
+	if err := req.Post(); err != nil {
+		t.Fatal(err)
+	}
+
+	var book map[string]interface{}
+
+	if err := req.DecodeJSON(&book); err != nil || book["request"] != 1.0 {
+		t.Errorf("Expected response to be read, got %v (%v) instead", book, err)
+	}
+
+	if err := hc.URL(s.URL, "books").Get(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	bin, err := ioutil.ReadFile(cassette)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, secret := range []string{"Bearer token", "abc", "s3cr3t"} {
+		if strings.Contains(string(bin), secret) {
+			t.Errorf("Expected %v to be scrubbed from cassette", secret)
+		}
+	}
+
+	replay, err := New(cassette, ModeReplay, ScrubHeaders("X-Session"), ScrubParams("token"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(replay.Interactions()) != 2 {
+		t.Errorf("Expected 2 interactions, got %d instead", len(replay.Interactions()))
+	}
+
+	hc = wedeploy.NewHTTPClient(wedeploy.WithHTTP(replay.Client()))
+	req = hc.URL(s.URL, "books").
+		Auth("other").
+		Param("token", "xyz").
+		Filter(filter.Equal("author", "Shakespeare")).
+		Filter(filter.Gt("year", 1600))
+
+	if err := req.Post(); err != nil {
+		t.Fatal(err)
+	}
+
+	book = nil
+
+	if err := req.DecodeJSON(&book); err != nil || book["request"] != 1.0 {
+		t.Errorf("Expected replayed response, got %v (%v) instead", book, err)
+	}
+
+	if req.Response.Header.Get("X-Session") != "REDACTED" {
+		t.Errorf("Expected scrubbed response header")
+	}
+
+	if requests != 2 {
+		t.Errorf("Expected replay not to send requests, got %d requests", requests)
+	}
+}
+
+func TestRecordAndReplayBinaryBody(t *testing.T) {
+	var dir = setupCassetteDir(t)
+	defer os.RemoveAll(dir)
+
+	var cassette = filepath.Join(dir, "gzip.json")
+	var want = `{"title":"Hamlet"}`
+
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var zr, err = gzip.NewReader(r.Body)
+
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		var body, _ = ioutil.ReadAll(zr)
+		w.Header().Set("Content-Encoding", "gzip")
+		var gz = gzip.NewWriter(w)
+		gz.Write(body)
+		gz.Close()
+	}))
+	defer s.Close()
+
+	var gzipped bytes.Buffer
+	var gz = gzip.NewWriter(&gzipped)
+	gz.Write([]byte(want))
+	gz.Close()
+
+	var send = func(c *http.Client) string {
+		var req, _ = http.NewRequest("POST", s.URL, bytes.NewReader(gzipped.Bytes()))
+		req.Header.Set("Content-Encoding", "gzip")
+		req.Header.Set("Accept-Encoding", "gzip")
+
+		var resp, err = c.Do(req)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		defer resp.Body.Close()
+
+		zr, err := gzip.NewReader(resp.Body)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var body, _ = ioutil.ReadAll(zr)
+		return string(body)
+	}
+
+	var r, err = New(cassette, ModeRecord)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := send(r.Client()); got != want {
+		t.Errorf("Expected body %v, got %v instead", want, got)
+	}
+
+	if err := r.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	replay, err := New(cassette, ModeReplay)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s.Close()
+
+	if got := send(replay.Client()); got != want {
+		t.Errorf("Expected replayed body %v, got %v instead", want, got)
+	}
+}
+
+func TestReplayMatchesNormalizedBody(t *testing.T) {
+	var dir = setupCassetteDir(t)
+	defer os.RemoveAll(dir)
+
+	var cassette = filepath.Join(dir, "cassette.json")
+	var content = `{"interactions": [
+		{
+			"request": {"method": "POST", "url": "http://example.com/books?b=2&a=1",
+				"body": "{\"limit\":1,\"filter\":[{\"year\":{\"operator\":\">\",\"value\":1600}}]}"},
+			"response": {"status": 201, "body": "first"}
+		},
+		{
+			"request": {"method": "POST", "url": "http://example.com/books?a=1&b=2",
+				"body": "{\"filter\":[{\"year\":{\"value\":1600,\"operator\":\">\"}}],\"limit\":1}"},
+			"response": {"status": 201, "body": "second"}
+		},
+		{
+			"request": {"method": "POST", "url": "http://example.com/form",
+				"body": "b=2&a=1"},
+			"response": {"status": 200, "body": "form"}
+		}
+	]}`
+
+	if err := ioutil.WriteFile(cassette, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var r, err = New(cassette, ModeReplay)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var hc = wedeploy.NewHTTPClient(wedeploy.WithHTTP(r.Client()))
+
+	for _, want := range []string{"first", "second", "second"} {
+		var req = hc.URL("http://example.com/books").
+			Param("a", "1").
+			Param("b", "2").
+			Filter(filter.Gt("year", 1600)).
+			Limit(1)
+
+		if err := req.Post(); err != nil {
+			t.Fatal(err)
+		}
+
+		var body, _ = ioutil.ReadAll(req.Response.Body)
+
+		if string(body) != want || req.Response.StatusCode != 201 {
+			t.Errorf("Expected replayed body %v, got %s instead", want, body)
+		}
+	}
+
+	var req = hc.URL("http://example.com/form").Form("a", "1").Form("b", "2")
+
+	if err := req.Post(); err != nil {
+		t.Error(err)
+	}
+
+	if err := hc.URL("http://example.com/missing").Get(); err == nil ||
+		!strings.Contains(err.Error(), ErrInteractionNotFound.Error()) {
+		t.Errorf("Expected interaction not found error, got %v instead", err)
+	}
+}
+
+func TestReplayMissingCassette(t *testing.T) {
+	if _, err := New("not-found.json", ModeReplay); !os.IsNotExist(err) {
+		t.Errorf("Expected not exist error, got %v instead", err)
+	}
+}
+
+func TestPassthrough(t *testing.T) {
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "live")
+	}))
+	defer s.Close()
+
+	var r, err = New("unused.json", ModePassthrough)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Mode() != ModePassthrough {
+		t.Errorf("Unexpected mode %v", r.Mode())
+	}
+
+	var resp, errg = r.Client().Get(s.URL)
+
+	if errg != nil {
+		t.Fatal(errg)
+	}
+
+	var body, _ = ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if string(body) != "live" || len(r.Interactions()) != 0 {
+		t.Errorf("Expected request not to be recorded")
+	}
+
+	if err := r.Stop(); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := os.Stat("unused.json"); !os.IsNotExist(err) {
+		t.Errorf("Expected no cassette to be saved")
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wedeploy
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+)
+
+// ErrStopStream can be returned by the function called by Stream
+// to stop reading the response without failing
+var ErrStopStream = errors.New("stop stream")
+
+// ErrNoResponse is returned when reading a request that has no response
+var ErrNoResponse = errors.New("request has no response")
+
+// ndjsonTypes are the media types of newline delimited JSON responses
+var ndjsonTypes = map[string]bool{
+	"application/x-ndjson": true,
+	"application/ndjson":   true,
+	"application/jsonl":    true,
+}
+
+// Stream decodes a JSON response element by element, calling fn for
+// each element of a top-level array, or for each value of a newline
+// delimited JSON (NDJSON) response, without reading it all into memory.
+// Any other JSON value is passed to fn as a single element.
+//
+// The response body is closed when Stream returns. If fn returns an error
+// Stream stops and returns it, unless it is ErrStopStream.
+func (w *WeDeploy) Stream(fn func(json.RawMessage) error) (err error) {
+	if w.Response == nil {
+		return ErrNoResponse
+	}
+
+	defer func() {
+		ec := w.Response.Body.Close()
+
+		if err == nil {
+			err = ec
+		}
+	}()
+
+	var r = bufio.NewReader(w.Response.Body)
+	var d = json.NewDecoder(r)
+
+	if w.streamArray(r) {
+		err = streamArray(d, fn)
+	} else {
+		err = streamValues(d, fn)
+	}
+
+	if err == ErrStopStream {
+		err = nil
+	}
+
+	return err
+}
+
+// streamArray checks if the response is a top-level array by peeking
+// at its first byte, unless it is a NDJSON response
+func (w *WeDeploy) streamArray(r *bufio.Reader) bool {
+	var mt, _, _ = mime.ParseMediaType(w.Response.Header.Get("Content-Type"))
+
+	if ndjsonTypes[mt] {
+		return false
+	}
+
+	for {
+		var b, err = r.Peek(1)
+
+		if err != nil {
+			return false
+		}
+
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			r.Discard(1)
+		default:
+			return b[0] == '['
+		}
+	}
+}
+
+func streamArray(d *json.Decoder, fn func(json.RawMessage) error) error {
+	// opening [
+	if _, err := d.Token(); err != nil {
+		return err
+	}
+
+	for d.More() {
+		var element json.RawMessage
+
+		if err := d.Decode(&element); err != nil {
+			return err
+		}
+
+		if err := fn(element); err != nil {
+			return err
+		}
+	}
+
+	// closing ]
+	_, err := d.Token()
+	return err
+}
+
+func streamValues(d *json.Decoder, fn func(json.RawMessage) error) error {
+	for {
+		var value json.RawMessage
+		var err = d.Decode(&value)
+
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if err := fn(value); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,216 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wedeploy
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+func streamResponse(contentType, body string) (*WeDeploy, *closeRecorder) {
+	var c = &closeRecorder{Reader: strings.NewReader(body)}
+	var w = URL("http://example.com/books")
+
+	w.Response = &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{contentType}},
+		Body:       c,
+	}
+
+	return w, c
+}
+
+func collectStream(w *WeDeploy) ([]string, error) {
+	var elements []string
+
+	var err = w.Stream(func(m json.RawMessage) error {
+		elements = append(elements, string(m))
+		return nil
+	})
+
+	return elements, err
+}
+
+func TestStream(t *testing.T) {
+	var cases = []struct {
+		contentType string
+		body        string
+		want        []string
+	}{
+		{
+			"application/json; charset=utf-8",
+			` [{"id":"1"}, {"id":"2","tags":["a","b"]},3]`,
+			[]string{`{"id":"1"}`, `{"id":"2","tags":["a","b"]}`, `3`},
+		},
+		{
+			"application/json",
+			`[]`,
+			nil,
+		},
+		{
+			"application/x-ndjson",
+			"{\"id\":\"1\"}\n{\"id\":\"2\"}\n",
+			[]string{`{"id":"1"}`, `{"id":"2"}`},
+		},
+		{
+			"application/x-ndjson",
+			"[1,2]\n[3]\n",
+			[]string{`[1,2]`, `[3]`},
+		},
+		{
+			"application/json",
+			"{\"id\":\"1\"}\n{\"id\":\"2\"}\n",
+			[]string{`{"id":"1"}`, `{"id":"2"}`},
+		},
+		{
+			"application/json",
+			`42`,
+			[]string{`42`},
+		},
+		{
+			"application/json",
+			``,
+			nil,
+		},
+	}
+
+	for _, c := range cases {
+		var w, body = streamResponse(c.contentType, c.body)
+		var got, err = collectStream(w)
+
+		if err != nil {
+			t.Errorf("Expected no error streaming %v, got %v instead", c.body, err)
+		}
+
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("Expected elements %v, got %v instead", c.want, got)
+		}
+
+		if !body.closed {
+			t.Errorf("Expected body of %v to be closed", c.body)
+		}
+	}
+}
+
+func TestStreamStop(t *testing.T) {
+	var w, body = streamResponse("application/json", `[1,2,3,4]`)
+	var got []string
+
+	var err = w.Stream(func(m json.RawMessage) error {
+		got = append(got, string(m))
+
+		if len(got) == 2 {
+			return ErrStopStream
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		t.Errorf("Expected no error, got %v instead", err)
+	}
+
+	if want := []string{"1", "2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected elements %v, got %v instead", want, got)
+	}
+
+	if !body.closed {
+		t.Errorf("Expected body to be closed on early exit")
+	}
+}
+
+func TestStreamCallbackError(t *testing.T) {
+	var w, body = streamResponse("application/x-ndjson", "1\n2\n")
+	var errCallback = errors.New("callback failure")
+
+	var err = w.Stream(func(m json.RawMessage) error {
+		return errCallback
+	})
+
+	if err != errCallback {
+		t.Errorf("Expected callback error, got %v instead", err)
+	}
+
+	if !body.closed {
+		t.Errorf("Expected body to be closed on error")
+	}
+}
+
+func TestStreamInvalidJSON(t *testing.T) {
+	for _, b := range []string{`[1,2`, `[1,}`, "1\n{"} {
+		var w, body = streamResponse("application/json", b)
+		var _, err = collectStream(w)
+
+		if err == nil {
+			t.Errorf("Expected error streaming %v", b)
+		}
+
+		if !body.closed {
+			t.Errorf("Expected body of %v to be closed on error", b)
+		}
+	}
+}
+
+func TestStreamNoResponse(t *testing.T) {
+	var w = URL("http://example.com/books")
+
+	if _, err := collectStream(w); err != ErrNoResponse {
+		t.Errorf("Expected error %v, got %v instead", ErrNoResponse, err)
+	}
+}
+
+func TestStreamRequest(t *testing.T) {
+	setupServer()
+	defer teardownServer()
+
+	mux.HandleFunc("/books", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"title":"Hamlet"},{"title":"Macbeth"}]`))
+	})
+
+	var req = URL("http://example.com/books")
+
+	if err := req.Get(); err != nil {
+		t.Fatal(err)
+	}
+
+	var titles []string
+
+	var err = req.Stream(func(m json.RawMessage) error {
+		var book struct {
+			Title string `json:"title"`
+		}
+
+		if err := json.Unmarshal(m, &book); err != nil {
+			return err
+		}
+
+		titles = append(titles, book.Title)
+		return nil
+	})
+
+	if err != nil {
+		t.Error(err)
+	}
+
+	if want := []string{"Hamlet", "Macbeth"}; !reflect.DeepEqual(titles, want) {
+		t.Errorf("Expected titles %v, got %v instead", want, titles)
+	}
+}
@@ -0,0 +1,404 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wedeploy
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SpanContext identifies a span of a trace, as in the W3C Trace Context
+type SpanContext struct {
+	TraceID [16]byte
+	SpanID  [8]byte
+	Sampled bool
+
+	// TraceState is the W3C tracestate header value, propagated as it is
+	TraceState string
+}
+
+// IsValid tells if the span context has a trace and span ID
+func (s SpanContext) IsValid() bool {
+	return s.TraceID != [16]byte{} && s.SpanID != [8]byte{}
+}
+
+// TraceParent is the W3C traceparent header value of the span context
+func (s SpanContext) TraceParent() string {
+	var flags = "00"
+
+	if s.Sampled {
+		flags = "01"
+	}
+
+	return fmt.Sprintf("00-%s-%s-%s",
+		hex.EncodeToString(s.TraceID[:]),
+		hex.EncodeToString(s.SpanID[:]),
+		flags)
+}
+
+// ParseTraceParent parses a W3C traceparent header value
+func ParseTraceParent(value string) (SpanContext, error) {
+	var s SpanContext
+	var parts = strings.Split(strings.TrimSpace(value), "-")
+
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" ||
+		(parts[0] == "00" && len(parts) != 4) ||
+		len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return s, fmt.Errorf("invalid traceparent %q", value)
+	}
+
+	var flags = make([]byte, 1)
+
+	for _, f := range []struct{ dst, src []byte }{
+		{s.TraceID[:], []byte(parts[1])},
+		{s.SpanID[:], []byte(parts[2])},
+		{flags, []byte(parts[3])},
+	} {
+		if _, err := hex.Decode(f.dst, f.src); err != nil {
+			return SpanContext{}, fmt.Errorf("invalid traceparent %q", value)
+		}
+	}
+
+	if !s.IsValid() {
+		return SpanContext{}, fmt.Errorf("invalid traceparent %q", value)
+	}
+
+	s.Sampled = flags[0]&1 == 1
+	return s, nil
+}
+
+// SpanContextFromHeader gets the span context of the W3C traceparent and
+// tracestate headers, such as of a request received by a server
+func SpanContextFromHeader(h http.Header) (SpanContext, bool) {
+	var value = h.Get("traceparent")
+
+	if value == "" {
+		return SpanContext{}, false
+	}
+
+	var s, err = ParseTraceParent(value)
+
+	if err != nil {
+		return SpanContext{}, false
+	}
+
+	s.TraceState = strings.Join(h["Tracestate"], ",")
+	return s, true
+}
+
+type spanContextKey struct{}
+
+// ContextWithSpanContext returns a copy of the context carrying the span
+// context, which becomes the parent of the request spans started from it
+func ContextWithSpanContext(ctx context.Context, s SpanContext) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, s)
+}
+
+// SpanContextFromContext gets the span context carried by the context
+func SpanContextFromContext(ctx context.Context) (SpanContext, bool) {
+	var s, ok = ctx.Value(spanContextKey{}).(SpanContext)
+	return s, ok
+}
+
+// Span of a request, as exported when it ends
+type Span struct {
+	Name        string
+	SpanContext SpanContext
+	Parent      SpanContext
+	Start       time.Time
+	End         time.Time
+	Attributes  map[string]interface{}
+	Err         error
+}
+
+// SpanExporter receives the spans of the requests
+type SpanExporter interface {
+	ExportSpan(Span)
+}
+
+// RequestMetric is the measurement of a request
+type RequestMetric struct {
+	Method     string
+	Route      string
+	QueryType  string
+	StatusCode int
+	Latency    time.Duration
+	Err        error
+}
+
+// MetricsExporter receives the measurements of the requests
+type MetricsExporter interface {
+	ExportMetric(RequestMetric)
+}
+
+// ParentExtractor gets the parent span context of the request spans from
+// the request context, such as an adapter for the spans of OpenTelemetry:
+//
+//	func(ctx context.Context) (wedeploy.SpanContext, bool) {
+//		var sc = trace.SpanContextFromContext(ctx)
+//		return wedeploy.SpanContext{
+//			TraceID:    sc.TraceID(),
+//			SpanID:     sc.SpanID(),
+//			Sampled:    sc.IsSampled(),
+//			TraceState: sc.TraceState().String(),
+//		}, sc.IsValid()
+//	}
+type ParentExtractor interface {
+	ParentSpanContext(ctx context.Context) (SpanContext, bool)
+}
+
+// ParentExtractorFunc is a function used as a ParentExtractor
+type ParentExtractorFunc func(ctx context.Context) (SpanContext, bool)
+
+// ParentSpanContext calls the function
+func (f ParentExtractorFunc) ParentSpanContext(ctx context.Context) (SpanContext, bool) {
+	return f(ctx)
+}
+
+// WithTracing starts a span for each request of the client, and sends it
+// with the W3C traceparent and tracestate headers.
+// The parent of the span is, in order, the span context got by the
+// ParentExtractor (see WithParentExtractor), the span context of the
+// request context (see ContextWithSpanContext), or the traceparent
+// header set on the request.
+func WithTracing(e SpanExporter) Option {
+	return func(h *HTTPClient) {
+		h.spans = e
+	}
+}
+
+// WithParentExtractor gets the parent of the request spans from the
+// request context with the extractor, such as from an OpenTelemetry span
+func WithParentExtractor(p ParentExtractor) Option {
+	return func(h *HTTPClient) {
+		h.parents = p
+	}
+}
+
+// WithMetrics measures the latency and errors of the requests of the client
+func WithMetrics(e MetricsExporter) Option {
+	return func(h *HTTPClient) {
+		h.metrics = e
+	}
+}
+
+// instrument the round trip of a request with tracing and metrics
+func (h *HTTPClient) instrument(w *WeDeploy, next roundTrip) roundTrip {
+	return func(req *http.Request) (*http.Response, error) {
+		var start = time.Now()
+		var attrs = map[string]interface{}{
+			"http.method": req.Method,
+			"http.url":    redactURL(req.URL),
+			"http.route":  w.getRoute(),
+		}
+
+		var queryType = w.queryType()
+
+		if queryType != "" {
+			attrs["wedeploy.query.type"] = queryType
+		}
+
+		var span Span
+
+		if h.spans != nil {
+			span = startSpan(h.parent(req), req.Method+" "+w.getRoute())
+			req = withSpan(req, span.SpanContext)
+		}
+
+		var resp, err = next(req)
+		var end = time.Now()
+		var statusCode int
+		var failure = err
+
+		if resp != nil {
+			statusCode = resp.StatusCode
+			attrs["http.status_code"] = statusCode
+		}
+
+		if failure == nil && statusCode >= 400 {
+			failure = StatusError{statusCode}
+		}
+
+		if h.spans != nil {
+			span.Start = start
+			span.End = end
+			span.Attributes = attrs
+			span.Err = failure
+			h.spans.ExportSpan(span)
+		}
+
+		if h.metrics != nil {
+			h.metrics.ExportMetric(RequestMetric{
+				Method:     req.Method,
+				Route:      w.getRoute(),
+				QueryType:  queryType,
+				StatusCode: statusCode,
+				Latency:    end.Sub(start),
+				Err:        failure,
+			})
+		}
+
+		return resp, err
+	}
+}
+
+// parent span context of the request span, if any
+func (h *HTTPClient) parent(req *http.Request) SpanContext {
+	if h.parents != nil {
+		if s, ok := h.parents.ParentSpanContext(req.Context()); ok && s.IsValid() {
+			return s
+		}
+	}
+
+	if s, ok := SpanContextFromContext(req.Context()); ok && s.IsValid() {
+		return s
+	}
+
+	var s, _ = SpanContextFromHeader(req.Header)
+	return s
+}
+
+func startSpan(parent SpanContext, name string) Span {
+	var span = Span{Name: name}
+
+	if parent.IsValid() {
+		span.Parent = parent
+		span.SpanContext.TraceID = parent.TraceID
+		span.SpanContext.Sampled = parent.Sampled
+		span.SpanContext.TraceState = parent.TraceState
+	} else {
+		randomID(span.SpanContext.TraceID[:])
+		span.SpanContext.Sampled = true
+	}
+
+	randomID(span.SpanContext.SpanID[:])
+	return span
+}
+
+// withSpan creates a copy of the request carrying the span context
+func withSpan(req *http.Request, s SpanContext) *http.Request {
+	var r = req.WithContext(ContextWithSpanContext(req.Context(), s))
+	r.Header = cloneHeader(req.Header)
+	r.Header.Set("traceparent", s.TraceParent())
+	r.Header.Del("tracestate")
+
+	if s.TraceState != "" {
+		r.Header.Set("tracestate", s.TraceState)
+	}
+
+	return r
+}
+
+func randomID(b []byte) {
+	for {
+		if _, err := rand.Read(b); err != nil {
+			panic(err)
+		}
+
+		for _, c := range b {
+			if c != 0 {
+				return
+			}
+		}
+	}
+}
+
+// redactURL removes the password from the URL
+func redactURL(u *url.URL) string {
+	if u.User == nil {
+		return u.String()
+	}
+
+	var c = *u
+	c.User = url.User(u.User.Username())
+	return c.String()
+}
+
+// MemoryExporter keeps spans and request metrics in memory, such as for tests
+type MemoryExporter struct {
+	mutex   sync.Mutex
+	spans   []Span
+	metrics []RequestMetric
+}
+
+// ExportSpan keeps the span
+func (m *MemoryExporter) ExportSpan(s Span) {
+	m.mutex.Lock()
+	m.spans = append(m.spans, s)
+	m.mutex.Unlock()
+}
+
+// ExportMetric keeps the request metric
+func (m *MemoryExporter) ExportMetric(r RequestMetric) {
+	m.mutex.Lock()
+	m.metrics = append(m.metrics, r)
+	m.mutex.Unlock()
+}
+
+// Spans exported
+func (m *MemoryExporter) Spans() []Span {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return append([]Span{}, m.spans...)
+}
+
+// Metrics exported
+func (m *MemoryExporter) Metrics() []RequestMetric {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return append([]RequestMetric{}, m.metrics...)
+}
+
+// Requests counter
+func (m *MemoryExporter) Requests() int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return len(m.metrics)
+}
+
+// Errors counter
+func (m *MemoryExporter) Errors() int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var errors int
+
+	for _, r := range m.metrics {
+		if r.Err != nil {
+			errors++
+		}
+	}
+
+	return errors
+}
+
+// Latency of all requests
+func (m *MemoryExporter) Latency() time.Duration {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var latency time.Duration
+
+	for _, r := range m.metrics {
+		latency += r.Latency
+	}
+
+	return latency
+}
+
+// Reset removes the spans and metrics exported
+func (m *MemoryExporter) Reset() {
+	m.mutex.Lock()
+	m.spans = nil
+	m.metrics = nil
+	m.mutex.Unlock()
+}
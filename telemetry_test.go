@@ -0,0 +1,296 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wedeploy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTracing(t *testing.T) {
+	var traceparent string
+
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+	}))
+	defer s.Close()
+
+	var parent, err = ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var exporter = &MemoryExporter{}
+	var hc = NewHTTPClient(WithTracing(exporter))
+	var req = hc.URL(s.URL, "books/1").Route("/books/{id}").Count()
+	req.SetContext(ContextWithSpanContext(context.Background(), parent))
+
+	if err := req.Post(); err != nil {
+		t.Fatal(err)
+	}
+
+	var spans = exporter.Spans()
+
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d instead", len(spans))
+	}
+
+	var span = spans[0]
+
+	if span.Name != "POST /books/{id}" {
+		t.Errorf("Unexpected span name %v", span.Name)
+	}
+
+	if span.Parent != parent || span.SpanContext.TraceID != parent.TraceID ||
+		span.SpanContext.SpanID == parent.SpanID || !span.SpanContext.Sampled {
+		t.Errorf("Expected span to be a child of %+v, got %+v instead", parent, span.SpanContext)
+	}
+
+	if traceparent != span.SpanContext.TraceParent() {
+		t.Errorf("Expected traceparent %v, got %v instead", span.SpanContext.TraceParent(), traceparent)
+	}
+
+	var want = map[string]interface{}{
+		"http.method":         "POST",
+		"http.url":            s.URL + "/books/1",
+		"http.route":          "/books/{id}",
+		"http.status_code":    200,
+		"wedeploy.query.type": "count",
+	}
+
+	for k, v := range want {
+		if span.Attributes[k] != v {
+			t.Errorf("Expected attribute %v = %v, got %v instead", k, v, span.Attributes[k])
+		}
+	}
+
+	if span.Err != nil || span.End.Before(span.Start) {
+		t.Errorf("Unexpected span %+v", span)
+	}
+
+	if req.Headers.Get("traceparent") != "" {
+		t.Errorf("Expected traceparent header not to leak to request headers")
+	}
+}
+
+func TestTracingNewTrace(t *testing.T) {
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer s.Close()
+
+	var exporter = &MemoryExporter{}
+	var hc = NewHTTPClient(WithTracing(exporter))
+
+	if err := hc.URL(s.URL, "books").Get(); err == nil {
+		t.Errorf("Expected error")
+	}
+
+	var span = exporter.Spans()[0]
+
+	if span.Parent.IsValid() || !span.SpanContext.IsValid() {
+		t.Errorf("Expected root span, got %+v", span)
+	}
+
+	if span.Name != "GET /books" {
+		t.Errorf("Unexpected span name %v", span.Name)
+	}
+
+	if span.Err != (StatusError{http.StatusNotFound}) {
+		t.Errorf("Expected span error to be status error, got %v instead", span.Err)
+	}
+
+	if _, ok := span.Attributes["wedeploy.query.type"]; ok {
+		t.Errorf("Expected no query type attribute")
+	}
+}
+
+func TestTracingParentExtractor(t *testing.T) {
+	var received http.Header
+
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header
+	}))
+	defer s.Close()
+
+	var parent, err = ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parent.TraceState = "vendor=value"
+
+	type otelKey struct{}
+
+	var exporter = &MemoryExporter{}
+	var hc = NewHTTPClient(
+		WithTracing(exporter),
+		WithParentExtractor(ParentExtractorFunc(func(ctx context.Context) (SpanContext, bool) {
+			var s, ok = ctx.Value(otelKey{}).(SpanContext)
+			return s, ok
+		})))
+
+	var req = hc.URL(s.URL, "books")
+	req.SetContext(context.WithValue(context.Background(), otelKey{}, parent))
+
+	if err := req.Get(); err != nil {
+		t.Fatal(err)
+	}
+
+	var span = exporter.Spans()[0]
+
+	if span.Parent != parent || span.SpanContext.TraceID != parent.TraceID ||
+		span.SpanContext.Sampled || span.SpanContext.TraceState != "vendor=value" {
+		t.Errorf("Expected span to be a child of %+v, got %+v instead", parent, span.SpanContext)
+	}
+
+	if received.Get("traceparent") != span.SpanContext.TraceParent() ||
+		received.Get("tracestate") != "vendor=value" {
+		t.Errorf("Expected trace context headers, got %v instead", received)
+	}
+}
+
+func TestTracingParentHeader(t *testing.T) {
+	var received http.Header
+
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header
+	}))
+	defer s.Close()
+
+	var exporter = &MemoryExporter{}
+	var hc = NewHTTPClient(WithTracing(exporter))
+	var req = hc.URL(s.URL, "books")
+	req.Header("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	req.Header("tracestate", "vendor=value")
+
+	if err := req.Get(); err != nil {
+		t.Fatal(err)
+	}
+
+	var span = exporter.Spans()[0]
+
+	if span.Parent.TraceParent() != "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01" ||
+		span.SpanContext.TraceID != span.Parent.TraceID {
+		t.Errorf("Expected span to be a child of the traceparent header, got %+v instead", span)
+	}
+
+	if received.Get("traceparent") != span.SpanContext.TraceParent() ||
+		received.Get("tracestate") != "vendor=value" {
+		t.Errorf("Expected trace context headers, got %v instead", received)
+	}
+}
+
+func TestSpanContextFromHeader(t *testing.T) {
+	var h = http.Header{}
+
+	if _, ok := SpanContextFromHeader(h); ok {
+		t.Errorf("Expected no span context")
+	}
+
+	h.Set("traceparent", "invalid")
+
+	if _, ok := SpanContextFromHeader(h); ok {
+		t.Errorf("Expected no span context for invalid traceparent")
+	}
+
+	h.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	h.Add("tracestate", "a=1")
+	h.Add("tracestate", "b=2")
+
+	var s, ok = SpanContextFromHeader(h)
+
+	if !ok || !s.Sampled || s.TraceState != "a=1,b=2" {
+		t.Errorf("Unexpected span context %+v", s)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	var s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer s.Close()
+
+	var exporter = &MemoryExporter{}
+	var hc = NewHTTPClient(WithMetrics(exporter))
+
+	if err := hc.URL(s.URL, "books").Limit(1).Get(); err != nil {
+		t.Error(err)
+	}
+
+	if err := hc.URL(s.URL, "fail").Get(); err == nil {
+		t.Errorf("Expected error")
+	}
+
+	if exporter.Requests() != 2 || exporter.Errors() != 1 {
+		t.Errorf("Expected 2 requests and 1 error, got %d and %d instead",
+			exporter.Requests(), exporter.Errors())
+	}
+
+	if exporter.Latency() <= 0 {
+		t.Errorf("Expected latency to be measured")
+	}
+
+	var m = exporter.Metrics()[0]
+
+	if m.Method != "GET" || m.Route != "/books" || m.QueryType != "fetch" || m.StatusCode != 200 {
+		t.Errorf("Unexpected metric %+v", m)
+	}
+
+	if len(exporter.Spans()) != 0 {
+		t.Errorf("Expected no spans without tracing")
+	}
+
+	exporter.Reset()
+
+	if exporter.Requests() != 0 {
+		t.Errorf("Expected exporter to be reset")
+	}
+}
+
+func TestParseTraceParent(t *testing.T) {
+	var valid = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	var s, err = ParseTraceParent(valid)
+
+	if err != nil || s.TraceParent() != valid || !s.Sampled {
+		t.Errorf("Expected %v, got %v (%v) instead", valid, s.TraceParent(), err)
+	}
+
+	var invalid = []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e47-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736zz-00f067aa0ba902-01",
+		"00-xbf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	}
+
+	for _, v := range invalid {
+		if _, err := ParseTraceParent(v); err == nil {
+			t.Errorf("Expected error parsing %q", v)
+		}
+	}
+}
+
+func TestRouteTemplate(t *testing.T) {
+	var template = URL("http://example.com/books").Route("/books/{id}").Template()
+
+	if route := template.Path("1").getRoute(); route != "/books/{id}" {
+		t.Errorf("Expected route to be kept, got %v instead", route)
+	}
+
+	if route := URL("http://example.com").getRoute(); route != "/" {
+		t.Errorf("Expected route /, got %v instead", route)
+	}
+}
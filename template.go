@@ -0,0 +1,71 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wedeploy
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/henvic/wedeploy-sdk-go/urilib"
+)
+
+// RequestTemplate is an immutable base for creating new requests.
+// It is safe for concurrent use by multiple goroutines.
+type RequestTemplate struct {
+	base *WeDeploy
+	body []byte
+	err  error
+}
+
+// Template creates a request template from a copy of the request.
+// Later changes to the request don't affect the template.
+// The body of the request is read into memory, and the request gets
+// a new body with the same content.
+func (w *WeDeploy) Template() *RequestTemplate {
+	var t = &RequestTemplate{
+		base: w.Clone(),
+	}
+
+	t.base.RequestBody = nil
+
+	if w.RequestBody != nil {
+		t.body, t.err = readBody(w.RequestBody)
+		w.RequestBody = t.newBody()
+	}
+
+	return t
+}
+
+// New creates a new request from the template
+func (t *RequestTemplate) New() *WeDeploy {
+	var w = t.base.Clone()
+
+	if t.body != nil || t.err != nil {
+		w.RequestBody = t.newBody()
+	}
+
+	return w
+}
+
+// Path creates a new request from the template composing paths
+func (t *RequestTemplate) Path(paths ...string) *WeDeploy {
+	var w = t.New()
+	w.URL = urilib.ResolvePath(w.URL, urilib.ResolvePath(paths...))
+	return w
+}
+
+// newBody for a request, which fails if the body couldn't be read
+func (t *RequestTemplate) newBody() io.Reader {
+	if t.err != nil {
+		return errReader{t.err}
+	}
+
+	return bytes.NewBuffer(append([]byte{}, t.body...))
+}
+
+// URL of the template
+func (t *RequestTemplate) URL() string {
+	return t.base.URL
+}
@@ -0,0 +1,146 @@
+// Copyright 2016-present Liferay, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wedeploy
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/henvic/wedeploy-sdk-go/jsonlib"
+)
+
+func TestTemplate(t *testing.T) {
+	req := URL("http://example.com/")
+	req.Auth("token")
+	req.Filter("tenant", "liferay")
+
+	tmpl := req.Template()
+
+	req.Header("X-Late", "change")
+
+	var got = tmpl.New()
+
+	if got.Headers.Get("Authorization") != "Bearer token" {
+		t.Errorf("Expected request to have template authorization")
+	}
+
+	if got.Headers.Get("X-Late") != "" {
+		t.Errorf("Expected template not to be affected by later changes")
+	}
+
+	got.Filter("name", "foo")
+
+	jsonlib.AssertJSONMarshal(t,
+		`{"filter": [{"tenant": {"operator": "=", "value": "liferay"}}]}`,
+		tmpl.New().Query)
+}
+
+func TestTemplatePath(t *testing.T) {
+	tmpl := URL("http://example.com/").Template()
+
+	var want = "http://example.com/books/1"
+
+	if got := tmpl.Path("books", "1").URL; got != want {
+		t.Errorf("Expected URL %v, got %v instead", want, got)
+	}
+
+	if got := tmpl.URL(); got != "http://example.com" {
+		t.Errorf("Expected template URL not to change, got %v instead", got)
+	}
+}
+
+func TestTemplateConcurrency(t *testing.T) {
+	setupServer()
+	defer teardownServer()
+
+	mux.HandleFunc("/url", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s", r.URL.Query().Get("n"))
+	})
+
+	req := URL("http://example.com/url")
+	req.Header("X-Base", "base")
+	tmpl := req.Template()
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+
+		go func(n int) {
+			defer wg.Done()
+
+			r := tmpl.New()
+			r.Param("n", strconv.Itoa(n))
+			r.Header("X-Request", strconv.Itoa(n))
+			r.Limit(n)
+
+			if err := r.Get(); err != nil {
+				t.Error(err)
+				return
+			}
+
+			assertTextualBody(t, strconv.Itoa(n), r.Response.Body)
+		}(i)
+	}
+
+	wg.Wait()
+}
+
+func TestTemplateBody(t *testing.T) {
+	setupServer()
+	defer teardownServer()
+
+	mux.HandleFunc("/url", func(w http.ResponseWriter, r *http.Request) {
+		var bin, _ = ioutil.ReadAll(r.Body)
+		w.Write(bin)
+	})
+
+	req := URL("http://example.com/url").Body(strings.NewReader("hello"))
+	tmpl := req.Template()
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			r := tmpl.New()
+
+			for j := 0; j < 2; j++ {
+				if err := r.Post(); err != nil {
+					t.Error(err)
+					return
+				}
+
+				assertTextualBody(t, "hello", r.Response.Body)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if err := req.Post(); err != nil {
+		t.Fatal(err)
+	}
+
+	assertTextualBody(t, "hello", req.Response.Body)
+}
+
+func TestTemplateBodyError(t *testing.T) {
+	var want = errors.New("broken")
+	var tmpl = URL("http://example.com/url").Body(errReader{want}).Template()
+
+	if _, err := ioutil.ReadAll(tmpl.New().RequestBody); err != want {
+		t.Errorf("Expected error %v, got %v instead", want, err)
+	}
+}
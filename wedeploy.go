@@ -33,7 +33,8 @@ var (
 	}
 )
 
-// StatusError is used for HTTP status code >= 400
+// StatusError is used for HTTP status code >= 400.
+// The response body is read into memory and closed, releasing the connection.
 type StatusError struct {
 	Code int
 }
@@ -60,21 +61,60 @@ type WeDeploy struct {
 	Response      *http.Response
 	context       context.Context
 	cancelTimeout *context.CancelFunc
+	client        *HTTPClient
 	httpClient    *http.Client
 	timeout       *time.Duration
+	route         string
+	idempotent    bool
+
+	maxResponseSize int64
+
+	// uncompressedBody of a request sent compressed, for logging and dumps
+	uncompressedBody []byte
+
+	// IdempotencyKey sent on the Idempotency-Key header, if any
+	IdempotencyKey string
 }
 
 // HTTPClient of the library
 type HTTPClient struct {
 	http      *http.Client
 	httpMutex sync.RWMutex
+	baseURL   string
+	headers   http.Header
+	userAgent string
+	cache     *responseCache
+	breaker   *circuitBreaker
+	spans     SpanExporter
+	parents   ParentExtractor
+	metrics   MetricsExporter
+	logger    *requestLogger
+	leaks     *LeakDetector
+
+	compression *CompressionSettings
+
+	idempotentMethods map[string]bool
+
+	dedup *flightGroup
+
+	maxResponseSize int64
+
+	limits     *requestLimiter
+	limitsOnce sync.Once
 }
 
 // NewHTTPClient to use an alternative HTTP Client
-func NewHTTPClient() *HTTPClient {
-	return &HTTPClient{
-		http: &http.Client{},
+func NewHTTPClient(opts ...Option) *HTTPClient {
+	var h = &HTTPClient{
+		http:    &http.Client{},
+		headers: http.Header{},
+	}
+
+	for _, o := range opts {
+		o(h)
 	}
+
+	return h
 }
 
 // HTTP gets the HTTP client
@@ -92,16 +132,23 @@ func (h *HTTPClient) SetHTTP(hc *http.Client) {
 	h.httpMutex.Unlock()
 }
 
+// BaseURL used to resolve relative request URLs
+func (h *HTTPClient) BaseURL() string {
+	return h.baseURL
+}
+
 // URL creates a new request object
+// Relative URIs are resolved against the base URL of the client, if any
 func (h *HTTPClient) URL(uri string, paths ...string) *WeDeploy {
 	var time = time.Now()
 	rand.Seed(time.UTC().UnixNano())
-	uri = urilib.ResolvePath(uri, urilib.ResolvePath(paths...))
+	uri = urilib.ResolvePath(h.resolve(uri), urilib.ResolvePath(paths...))
 
 	var w = &WeDeploy{
 		ID:         rand.Int(),
 		Time:       time,
 		URL:        uri,
+		client:     h,
 		httpClient: h.HTTP(),
 	}
 
@@ -111,9 +158,29 @@ func (h *HTTPClient) URL(uri string, paths ...string) *WeDeploy {
 	w.Headers.Set("Content-Type", "application/json; charset=utf-8")
 	w.Headers.Set("Accept", "application/json")
 
+	if h.userAgent != "" {
+		w.Headers.Set("User-Agent", h.userAgent)
+	}
+
+	for key, values := range h.headers {
+		w.Headers[key] = append([]string{}, values...)
+	}
+
 	return w
 }
 
+func (h *HTTPClient) resolve(uri string) string {
+	if h.baseURL == "" {
+		return uri
+	}
+
+	if u, err := url.Parse(uri); err == nil && u.IsAbs() {
+		return uri
+	}
+
+	return urilib.ResolvePath(h.baseURL, uri)
+}
+
 // URL creates a new request object
 func URL(uri string, paths ...string) *WeDeploy {
 	return client.URL(uri, paths...)
@@ -127,12 +194,7 @@ func (w *WeDeploy) Aggregate(ai ...interface{}) *WeDeploy {
 
 // Auth sets HTTP basic auth headers
 func (w *WeDeploy) Auth(args ...string) *WeDeploy {
-	switch len(args) {
-	case 1:
-		w.Header("Authorization", "Bearer "+args[0])
-	default:
-		w.Header("Authorization", "Basic "+basicAuth(args[0], args[1]))
-	}
+	w.Header("Authorization", authorization(args...))
 	return w
 }
 
@@ -142,6 +204,56 @@ func (w *WeDeploy) Body(body io.Reader) *WeDeploy {
 	return w
 }
 
+// Clone creates a copy of the request that can be changed and sent
+// independently. Headers, form values and query are deep copied,
+// as well as a *bytes.Buffer body (other bodies are shared).
+// The copy gets a new ID and has no Request or Response.
+func (w *WeDeploy) Clone() *WeDeploy {
+	var c = &WeDeploy{
+		ID:          rand.Int(),
+		Time:        time.Now(),
+		URL:         w.URL,
+		RequestBody: w.RequestBody,
+		context:     w.context,
+		client:      w.client,
+		httpClient:  w.httpClient,
+		route:       w.route,
+		idempotent:  w.idempotent,
+
+		maxResponseSize: w.maxResponseSize,
+	}
+
+	if bb, ok := w.RequestBody.(*bytes.Buffer); ok {
+		c.RequestBody = bytes.NewBuffer(append([]byte{}, bb.Bytes()...))
+	}
+
+	if w.Headers != nil {
+		c.Headers = cloneHeader(w.Headers)
+		c.Headers.Del(IdempotencyKeyHeader)
+	}
+
+	if w.FormValues != nil {
+		var fv = url.Values{}
+
+		for k, v := range *w.FormValues {
+			fv[k] = append([]string{}, v...)
+		}
+
+		c.FormValues = &fv
+	}
+
+	if w.Query != nil {
+		c.Query = w.Query.Clone()
+	}
+
+	if w.timeout != nil {
+		var timeout = *w.timeout
+		c.timeout = &timeout
+	}
+
+	return c
+}
+
 // Count adds a Count query to the request
 func (w *WeDeploy) Count() *WeDeploy {
 	w.getOrCreateQuery().Count()
@@ -248,8 +360,9 @@ func (w *WeDeploy) Patch() error {
 }
 
 // Path creates a new WeDeploy object composing paths
+// The new object is bound to the same HTTPClient
 func (w *WeDeploy) Path(paths ...string) *WeDeploy {
-	return URL(w.URL, paths...)
+	return w.getClient().URL(w.URL, paths...)
 }
 
 // Post method
@@ -272,6 +385,13 @@ func (w *WeDeploy) Context() context.Context {
 	return w.context
 }
 
+// Route sets the URL template of the request, such as /books/{id},
+// used to identify it on traces and metrics instead of its URL path
+func (w *WeDeploy) Route(template string) *WeDeploy {
+	w.route = template
+	return w
+}
+
 // Sort adds a Sort query to the request
 func (w *WeDeploy) Sort(field string, direction ...string) *WeDeploy {
 	w.getOrCreateQuery().Sort(field, direction...)
@@ -283,6 +403,27 @@ func (w *WeDeploy) Timeout(timeout time.Duration) {
 	w.timeout = &timeout
 }
 
+// authorization creates the Authorization header value
+// from a token or from an username and password
+func authorization(args ...string) string {
+	if len(args) == 1 {
+		return "Bearer " + args[0]
+	}
+
+	return "Basic " + basicAuth(args[0], args[1])
+}
+
+// cloneHeader creates a deep copy of the header
+func cloneHeader(h http.Header) http.Header {
+	var c = http.Header{}
+
+	for k, v := range h {
+		c[k] = append([]string{}, v...)
+	}
+
+	return c
+}
+
 // basicAuth creates the basic auth parameter
 // extracted from golang/go/src/net/http/client.go
 func basicAuth(username, password string) string {
@@ -290,6 +431,37 @@ func basicAuth(username, password string) string {
 	return base64.StdEncoding.EncodeToString([]byte(auth))
 }
 
+func (w *WeDeploy) getClient() *HTTPClient {
+	if w.client == nil {
+		return client
+	}
+
+	return w.client
+}
+
+func (w *WeDeploy) getRoute() string {
+	if w.route != "" {
+		return w.route
+	}
+
+	if u, err := url.Parse(w.URL); err == nil && u.Path != "" {
+		return u.Path
+	}
+
+	return "/"
+}
+
+func (w *WeDeploy) queryType() string {
+	switch {
+	case w.Query == nil:
+		return ""
+	case w.Query.Type == "":
+		return "fetch"
+	}
+
+	return w.Query.Type
+}
+
 func (w *WeDeploy) getOrCreateQuery() *query.Builder {
 	if w.Query == nil {
 		w.Query = query.New()
@@ -319,20 +491,58 @@ func (w *WeDeploy) action(method string) (err error) {
 		bb = bytes.NewBuffer(w.RequestBody.(*bytes.Buffer).Bytes())
 	}
 
-	w.Response, err = w.httpClient.Do(w.Request)
+	w.Response, err = w.getClient().do(w)
 	w.cancelRemainingTimeout()
 
 	if bb != nil {
 		w.RequestBody = bb
 	}
 
-	if err == nil && w.Response.StatusCode >= 400 {
-		err = StatusError{w.Response.StatusCode}
+	if err == nil {
+		err = w.handleResponseBody()
 	}
 
 	return err
 }
 
+// roundTrip sends a request and returns its response
+type roundTrip func(*http.Request) (*http.Response, error)
+
+// do sends the request through the layers enabled on the client
+func (h *HTTPClient) do(w *WeDeploy) (*http.Response, error) {
+	var rt roundTrip = w.httpClient.Do
+
+	if h.compression != nil {
+		rt = h.compression.wrap(rt)
+	}
+
+	if h.cache != nil {
+		rt = h.cache.wrap(w, rt)
+	}
+
+	if l := h.limiter(); l.enabled() {
+		rt = l.wrap(rt)
+	}
+
+	if h.breaker != nil {
+		rt = h.breaker.wrap(rt)
+	}
+
+	if h.dedup != nil {
+		rt = h.dedup.wrap(w, rt)
+	}
+
+	if h.spans != nil || h.metrics != nil {
+		rt = h.instrument(w, rt)
+	}
+
+	if h.logger != nil {
+		rt = h.logger.wrap(w, rt)
+	}
+
+	return rt(w.Request)
+}
+
 func (w *WeDeploy) setupContext() {
 	if w.context == nil {
 		w.context = context.Background()
@@ -367,7 +577,22 @@ func (w *WeDeploy) setupAction(method string) (err error) {
 		w.RequestBody = bytes.NewReader(bin)
 	}
 
-	if w.Request, err = http.NewRequest(method, w.URL, w.RequestBody); err != nil {
+	if err = w.setupIdempotencyKey(method); err != nil {
+		return err
+	}
+
+	var body = w.RequestBody
+	w.uncompressedBody = nil
+
+	if c := w.getClient().compression; c != nil {
+		if body, w.uncompressedBody, err = c.compressBody(body, w.Headers); err != nil {
+			return err
+		}
+	}
+
+	var compressed = w.uncompressedBody != nil
+
+	if w.Request, err = http.NewRequest(method, w.URL, body); err != nil {
 		return err
 	}
 
@@ -375,6 +600,18 @@ func (w *WeDeploy) setupAction(method string) (err error) {
 	w.setupRequestTimeout()
 	w.Request.Header = w.Headers
 
+	if compressed || w.IdempotencyKey != "" {
+		w.Request.Header = cloneHeader(w.Headers)
+	}
+
+	if compressed {
+		w.Request.Header.Set("Content-Encoding", "gzip")
+	}
+
+	if w.IdempotencyKey != "" {
+		w.Request.Header.Set(IdempotencyKeyHeader, w.IdempotencyKey)
+	}
+
 	return err
 }
 
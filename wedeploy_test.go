@@ -22,6 +22,7 @@ import (
 
 	"github.com/henvic/wedeploy-sdk-go/aggregation"
 	"github.com/henvic/wedeploy-sdk-go/filter"
+	"github.com/henvic/wedeploy-sdk-go/geo"
 	"github.com/henvic/wedeploy-sdk-go/jsonlib"
 	"github.com/henvic/wedeploy-sdk-go/query"
 	"github.com/kylelemons/godebug/pretty"
@@ -85,6 +86,75 @@ func TestHeader(t *testing.T) {
 
 }
 
+func TestClone(t *testing.T) {
+	req := URL("http://example.com/foo")
+	req.Header("X-Custom", "foo")
+	req.Form("a", "b")
+	req.Filter("name", "foo")
+	req.Body(bytes.NewBufferString("body"))
+	req.Timeout(time.Second)
+
+	ctx := context.WithValue(context.Background(), struct{}{}, "cool")
+	req.SetContext(ctx)
+
+	c := req.Clone()
+
+	if c.ID == req.ID {
+		t.Errorf("Expected clone to have a new ID")
+	}
+
+	if c.URL != req.URL || c.Context() != ctx || *c.timeout != *req.timeout {
+		t.Errorf("Expected clone to have the same URL, context and timeout")
+	}
+
+	c.Header("X-Custom", "bar")
+	c.Form("a", "c")
+	c.Filter("name", "bar")
+	c.Limit(10)
+	c.RequestBody.(*bytes.Buffer).WriteString("changed")
+
+	if got := req.Headers["X-Custom"]; len(got) != 1 {
+		t.Errorf("Expected original headers not to change, got %v", got)
+	}
+
+	if got := (*req.FormValues)["a"]; len(got) != 1 {
+		t.Errorf("Expected original form values not to change, got %v", got)
+	}
+
+	jsonlib.AssertJSONMarshal(t,
+		`{"filter": [{"name": {"operator": "=", "value": "foo"}}]}`,
+		req.Query)
+
+	if got := req.RequestBody.(*bytes.Buffer).String(); got != "body" {
+		t.Errorf("Expected original body not to change, got %v", got)
+	}
+}
+
+func TestCloneSend(t *testing.T) {
+	setupServer()
+	defer teardownServer()
+
+	mux.HandleFunc("/url", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s", r.Header.Get("X-Custom"))
+	})
+
+	req := URL("http://example.com/url")
+	req.Header("X-Custom", "foo")
+
+	c := req.Clone()
+	c.Headers.Set("X-Custom", "bar")
+
+	if err := c.Get(); err != nil {
+		t.Error(err)
+	}
+
+	if req.Request != nil || req.Response != nil {
+		t.Errorf("Expected original request not to be sent")
+	}
+
+	assertTextualBody(t, "bar", c.Response.Body)
+}
+
 func TestContext(t *testing.T) {
 	req := URL("https://example.com/")
 
@@ -766,6 +836,19 @@ func TestQueryInvalidStructure(t *testing.T) {
 	}
 }
 
+func TestQueryInvalidDistanceUnit(t *testing.T) {
+	req := URL("http://example.com/foo/bah")
+	req.Filter(filter.Distance("location", geo.NewPoint(0, 0), "5 furlongs"))
+
+	if err := req.Get(); err == nil {
+		t.Error("Expected Get() to fail due to invalid distance unit")
+	}
+
+	if req.Response != nil {
+		t.Errorf("Expected request not to be sent, got response %v", req.Response)
+	}
+}
+
 func TestQueryString(t *testing.T) {
 	setupServer()
 	defer teardownServer()